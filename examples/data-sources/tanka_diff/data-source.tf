@@ -0,0 +1,13 @@
+data "tanka_diff" "production" {
+  source_path = "tanka/environments/production"
+  namespace   = "production"
+  config      = "file://config/production.json"
+
+  strategy        = "server"
+  with_prune      = true
+  fail_on_changes = true
+}
+
+output "changed_objects" {
+  value = [for change in data.tanka_diff.production.changes : "${change.action} ${change.kind}/${change.name}"]
+}
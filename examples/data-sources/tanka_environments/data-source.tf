@@ -0,0 +1,11 @@
+data "tanka_environments" "infra" {
+  path     = "tanka/environments"
+  selector = "team=infra"
+}
+
+resource "tanka_release" "infra" {
+  for_each = { for environment in data.tanka_environments.infra.environments : environment.name => environment }
+
+  source_path = each.value.path
+  namespace   = each.value.namespace
+}
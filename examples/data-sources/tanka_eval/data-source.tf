@@ -0,0 +1,24 @@
+data "tanka_eval" "ports" {
+  snippet = "(import 'ports.libsonnet').services"
+}
+
+data "tanka_eval" "image" {
+  path       = "tanka/environments/default"
+  expression = "data.deployment.spec.template.spec.containers[0].image"
+
+  tla_str = {
+    apiServer = "https://127.0.0.1:6443"
+    namespace = "default"
+  }
+  tla_code = {
+    tf_config = jsonencode({ replicas = 2 })
+  }
+}
+
+output "api_port" {
+  value = data.tanka_eval.ports.result.api
+}
+
+output "image" {
+  value = data.tanka_eval.image.result
+}
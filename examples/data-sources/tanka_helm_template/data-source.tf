@@ -0,0 +1,18 @@
+data "tanka_helm_template" "grafana" {
+  chart     = "tanka/charts/grafana"
+  name      = "grafana"
+  namespace = "monitoring"
+
+  values_object = {
+    replicas = 2
+    persistence = {
+      enabled = true
+    }
+  }
+  api_versions = ["monitoring.coreos.com/v1"]
+  include_crds = true
+}
+
+output "grafana_kinds" {
+  value = [for m in data.tanka_helm_template.grafana.manifests : m.kind]
+}
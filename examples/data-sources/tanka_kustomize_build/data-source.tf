@@ -0,0 +1,14 @@
+data "tanka_kustomize_build" "ingress" {
+  path = "kustomize/overlays/prod"
+}
+
+data "tanka_manifests" "default" {
+  source_path = "tanka/environments/default"
+}
+
+locals {
+  all_manifests = concat(
+    data.tanka_kustomize_build.ingress.manifests,
+    data.tanka_manifests.default.manifests,
+  )
+}
@@ -0,0 +1,17 @@
+data "tanka_lint" "project" {
+  paths = ["tanka/environments", "tanka/lib"]
+}
+
+data "tanka_lint" "strict" {
+  paths            = ["tanka/lib"]
+  format_check     = false
+  fail_on_findings = true
+}
+
+output "lint_passed" {
+  value = data.tanka_lint.project.passed
+}
+
+output "lint_findings" {
+  value = [for f in data.tanka_lint.project.findings : "${f.file}:${f.line}:${f.column}: ${f.message}"]
+}
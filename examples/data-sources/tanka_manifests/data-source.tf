@@ -0,0 +1,22 @@
+data "tanka_manifests" "default" {
+  source_path = "tanka/environments/default"
+  namespace   = "monitoring"
+
+  config_layers = [
+    "file://config/defaults.json",
+    jsonencode({
+      replicas : 3
+    }),
+  ]
+
+  targets = ["deployment/.*", "service/.*"]
+}
+
+output "manifest_kinds" {
+  value = [for manifest in data.tanka_manifests.default.manifests : manifest.kind]
+}
+
+resource "local_file" "rendered" {
+  filename = "rendered.yaml"
+  content  = data.tanka_manifests.default.yaml
+}
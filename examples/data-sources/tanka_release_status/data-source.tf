@@ -0,0 +1,16 @@
+data "tanka_release_status" "blue" {
+  source_path = "tanka/environments/blue"
+  namespace   = "blue"
+}
+
+resource "aws_route53_record" "app" {
+  zone_id = var.zone_id
+  name    = "app.example.com"
+  type    = "CNAME"
+  ttl     = 60
+  records = [data.tanka_release_status.blue.healthy ? "blue.example.com" : "green.example.com"]
+}
+
+output "blue_images" {
+  value = distinct(flatten([for object in data.tanka_release_status.blue.objects : object.images]))
+}
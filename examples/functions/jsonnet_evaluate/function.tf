@@ -0,0 +1,13 @@
+locals {
+  snippet = <<-EOT
+    function(replicas) {
+      replicas: replicas,
+      ports: std.native('parseYaml')(importstr 'ports.yaml')[0],
+    }
+  EOT
+}
+
+# { replicas = 3, ports = [{ name = "http", port = 8080 }] }
+output "deployment_settings" {
+  value = provider::tanka::jsonnet_evaluate(local.snippet, { replicas = 3 })
+}
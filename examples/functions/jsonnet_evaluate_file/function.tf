@@ -0,0 +1,6 @@
+# Evaluates tanka/environments/default/main.jsonnet with a top-level argument
+output "environment" {
+  value = provider::tanka::jsonnet_evaluate_file("${path.module}/tanka/environments/default", {
+    cluster = "production"
+  })
+}
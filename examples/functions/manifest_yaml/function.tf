@@ -0,0 +1,21 @@
+locals {
+  manifests = [
+    {
+      apiVersion = "apps/v1"
+      kind       = "Deployment"
+      metadata   = { name = "web" }
+      spec       = { replicas = 2 }
+    },
+    {
+      apiVersion = "v1"
+      kind       = "Service"
+      metadata   = { name = "web" }
+    },
+  ]
+}
+
+# The Service is printed before the Deployment, the same way `tk show` would
+resource "local_file" "manifests" {
+  filename = "${path.module}/manifests.yaml"
+  content  = provider::tanka::manifest_yaml(local.manifests)
+}
@@ -0,0 +1,22 @@
+locals {
+  defaults = {
+    replicas = 1
+    image = {
+      repository = "nginx"
+      tag        = "1.27"
+    }
+    debug = true
+  }
+
+  overrides = {
+    image = {
+      tag = "1.28"
+    }
+    debug = null
+  }
+}
+
+# { replicas = 1, image = { repository = "nginx", tag = "1.28" } }
+output "effective_config" {
+  value = provider::tanka::merge_patch(local.defaults, local.overrides)
+}
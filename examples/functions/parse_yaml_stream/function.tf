@@ -0,0 +1,11 @@
+data "tanka_kustomize_build" "app" {
+  path = "kustomize/app"
+}
+
+locals {
+  manifests = provider::tanka::parse_yaml_stream(data.tanka_kustomize_build.app.yaml)
+}
+
+output "deployment_names" {
+  value = [for m in local.manifests : m.metadata.name if m.kind == "Deployment"]
+}
@@ -0,0 +1,16 @@
+resource "tanka_export" "all" {
+  source_path = "tanka/environments"
+  output_dir  = "manifests"
+  format      = "{{env.metadata.name}}/{{.kind}}-{{.metadata.name}}"
+}
+
+resource "tanka_export" "infra" {
+  source_path    = "tanka/environments"
+  output_dir     = "gitops/clusters/prod"
+  selector       = "team=infra"
+  merge_strategy = "replace-envs"
+
+  config = jsonencode({
+    replicas : 3
+  })
+}
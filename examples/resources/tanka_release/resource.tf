@@ -17,3 +17,67 @@ resource "tanka_release" "config_override_external_file " {
   })
   config_override = "file://tanka_config_override.json"
 }
+
+resource "tanka_release" "sensitive_config" {
+  config = jsonencode({
+    database_host : "db.example.com"
+  })
+  sensitive_config = jsonencode({
+    database_password : var.database_password
+  })
+}
+
+resource "tanka_release" "sensitive_config_sops" {
+  config           = "file://config/prod.json"
+  sensitive_config = "file://config/prod.secrets.sops.yaml"
+}
+
+resource "tanka_release" "config_authenticated_remote" {
+  config = "https://config.example.com/tanka/config.json"
+
+  config_source {
+    url          = "https://config.example.com/tanka/config.json"
+    bearer_token = var.config_token
+    timeout      = "10s"
+    retries      = 3
+    sha256       = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+  }
+}
+
+resource "tanka_release" "config_object" {
+  config_object = {
+    replicas = 2
+    image = {
+      repository = "nginx"
+      tag        = "1.27"
+    }
+  }
+  config_override_object = {
+    replicas = 3
+  }
+}
+
+resource "tanka_release" "config_git_and_env" {
+  config          = "git::https://github.com/example/tanka-config.git//environments/prod.json?ref=v1.2.0"
+  config_override = "yaml+env://TANKA_CONFIG_OVERRIDE"
+}
+
+resource "tanka_release" "config_layers" {
+  config_layers = [
+    "file://config/org_defaults.json",
+    "file://config/cluster_defaults.yaml",
+    jsonencode({
+      replicas : 3
+    }),
+    var.emergency_override,
+  ]
+}
+
+resource "tanka_release" "checked" {
+  lint         = "warning"
+  format_check = "error"
+}
+
+resource "tanka_release" "helm_charts" {
+  helm_charts = "vendor"
+}
@@ -2,14 +2,24 @@ package provider
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/grafana/tanka/pkg/kubernetes/client"
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+	"github.com/grafana/tanka/pkg/process"
 	"github.com/grafana/tanka/pkg/tanka"
 )
 
@@ -17,13 +27,17 @@ type Client struct {
 	Endpoint             string
 	Token                string
 	ClusterCaCertificate string
+	BaseDir              string
+	SopsAgeKeyFile       string
 }
 
-func NewClient(endpoint, token, cluster_ca_certificate *string) (client *Client, err error) {
+func NewClient(endpoint, token, cluster_ca_certificate, base_dir, sops_age_key_file *string) (client *Client, err error) {
 	c := Client{
 		Endpoint:             *endpoint,
 		Token:                *token,
 		ClusterCaCertificate: *cluster_ca_certificate,
+		BaseDir:              *base_dir,
+		SopsAgeKeyFile:       *sops_age_key_file,
 	}
 
 	c.setCredentials()
@@ -67,13 +81,17 @@ func (c *Client) setCredentials() {
 	}
 }
 
-func createBaseOpts(api_server, namespace, config, config_override string) (opts tanka.ApplyBaseOpts) {
+func createBaseOpts(api_server, namespace, config, config_override, sensitive_config string) (opts tanka.ApplyBaseOpts) {
 
 	var TLACode jsonnet.InjectedCode
 	TLACode.Set("apiServer", "\""+api_server+"\"")
 	TLACode.Set("namespace", "\""+namespace+"\"")
 	TLACode.Set("tf_config", config)
 	TLACode.Set("tf_config_override", config_override)
+	// Only injected when set, so packages without the parameter keep working
+	if sensitive_config != "" {
+		TLACode.Set("tf_sensitive_config", sensitive_config)
+	}
 	opts.TLACode = TLACode
 
 	opts.AutoApprove = "true"
@@ -83,14 +101,19 @@ func createBaseOpts(api_server, namespace, config, config_override string) (opts
 	return
 }
 
-func (c *Client) Apply(api_server, namespace, config, config_override, baseDir string) (err error) {
-	opts := createBaseOpts(api_server, namespace, config, config_override)
+func (c *Client) Apply(api_server, namespace, config, config_override, sensitive_config, baseDir string) (err error) {
+	opts := createBaseOpts(api_server, namespace, config, config_override, sensitive_config)
 
 	var applyOpts tanka.ApplyOpts
 	applyOpts.ApplyBaseOpts = opts
 
 	applyOpts.ApplyStrategy = "server"
 
+	// The diff printed before applying would expose rendered sensitive values
+	if sensitive_config != "" {
+		applyOpts.DiffStrategy = "none"
+	}
+
 	err = tanka.Apply(baseDir, applyOpts)
 	if err != nil {
 		return err
@@ -99,8 +122,8 @@ func (c *Client) Apply(api_server, namespace, config, config_override, baseDir s
 	return
 }
 
-func (c *Client) Delete(api_server, namespace, config, config_override, baseDir string) (err error) {
-	opts := createBaseOpts(api_server, namespace, config, config_override)
+func (c *Client) Delete(api_server, namespace, config, config_override, sensitive_config, baseDir string) (err error) {
+	opts := createBaseOpts(api_server, namespace, config, config_override, sensitive_config)
 
 	var deleteOpts tanka.DeleteOpts
 	deleteOpts.ApplyBaseOpts = opts
@@ -113,55 +136,327 @@ func (c *Client) Delete(api_server, namespace, config, config_override, baseDir
 	return
 }
 
-func (c *Client) parseConfig(config_input string) (config string, err error) {
+// Show evaluates the tanka environment without contacting the cluster. The
+// name selects one of several inline environments and the targets filter the
+// manifests, both are optional.
+func (c *Client) Show(api_server, namespace, config, config_override, sensitive_config, baseDir, name string, targets []string) (manifests manifest.List, err error) {
+	opts := createBaseOpts(api_server, namespace, config, config_override, sensitive_config)
+	opts.Name = name
 
-	config_type := "json"
-	if strings.HasPrefix(config_input, "http://") || strings.HasPrefix(config_input, "https://") || strings.HasPrefix(config_input, "file://") {
-		split := strings.Split(config_input, "://")
-		config_type = split[0]
+	opts.Filters, err = process.StrExps(targets...)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err = tanka.Show(baseDir, opts.Opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Diff compares the tanka environment with the live state of the cluster and
+// returns the differences in `diff(1)` format, or nil when there are none.
+// An empty strategy uses the one of the environment.
+func (c *Client) Diff(api_server, namespace, config, config_override, sensitive_config, baseDir, name string, targets []string, strategy string, with_prune bool) (diff *string, err error) {
+	opts := createBaseOpts(api_server, namespace, config, config_override, sensitive_config)
+	opts.Name = name
+
+	opts.Filters, err = process.StrExps(targets...)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffOpts tanka.DiffOpts
+	diffOpts.Opts = opts.Opts
+	diffOpts.Strategy = strategy
+	diffOpts.WithPrune = with_prune
+
+	diff, err = tanka.Diff(baseDir, diffOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Status fetches the live objects carrying the `tanka.dev/environment` label of
+// the tanka environment from the cluster, the same way tanka finds the
+// objects to prune. Like pruning, it requires `spec.injectLabels`.
+func (c *Client) Status(api_server, namespace, config, config_override, sensitive_config, baseDir, name string) (objects manifest.List, err error) {
+	opts := createBaseOpts(api_server, namespace, config, config_override, sensitive_config)
+	opts.Name = name
+
+	env, err := tanka.LoadEnvironment(baseDir, opts.Opts)
+	if err != nil {
+		return nil, err
+	}
+	if !env.Spec.InjectLabels {
+		return nil, errors.New("spec.injectLabels of the environment is not enabled, the objects can only be found by the label it adds")
+	}
+
+	var ctl *client.Kubectl
+	if len(env.Spec.ContextNames) < 1 {
+		ctl, err = client.New(env.Spec.APIServer)
+	} else {
+		ctl, err = client.NewFromNames(env.Spec.ContextNames)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer ctl.Close()
+
+	resources, err := ctl.Resources()
+	if err != nil {
+		return nil, err
+	}
+
+	var kinds []string
+	for _, resource := range resources {
+		if strings.Contains(resource.Verbs, "list") {
+			kinds = append(kinds, resource.FQN())
+		}
+	}
+
+	return ctl.GetByLabels("", strings.Join(kinds, ","), map[string]string{
+		process.LabelEnvironment: env.Metadata.NameLabel(),
+	})
+}
+
+// configProtocols are the recognised prefixes of config inputs. The `yaml+`
+// variants force the source to be read as YAML.
+var configProtocols = []string{"file", "http", "https", "env", "yaml", "yaml+file", "yaml+http", "yaml+https", "yaml+env"}
+
+// configProtocol returns the protocol prefix of a config input, "json" is
+// used for inline data.
+func configProtocol(config_input string) string {
+	for _, protocol := range configProtocols {
+		if strings.HasPrefix(config_input, protocol+"://") {
+			return protocol
+		}
+	}
+
+	// Git sources follow the syntax of Terraform module sources
+	for _, protocol := range []string{"git", "yaml+git"} {
+		if strings.HasPrefix(config_input, protocol+"::") {
+			return protocol
+		}
+	}
+
+	return "json"
+}
+
+// ParseOpts are the settings used to resolve config inputs.
+type ParseOpts struct {
+	// BaseDir is the directory relative file paths are resolved against
+	BaseDir string
+	// Sources hold the settings for fetching remote sources
+	Sources []ConfigSource
+	// Revisions records the commit each git source resolved to, when set
+	Revisions map[string]string
+	// Decrypt accepts SOPS encrypted sources, only set where the plaintext is
+	// never persisted
+	Decrypt bool
+}
+
+// resolvePath resolves a relative path against the base directory. The base
+// directory itself is relative to the working directory, which is also used
+// when no base directory is set.
+func resolvePath(base_dir, file_path string) string {
+	if !filepath.IsAbs(file_path) {
+		file_path = filepath.Join(base_dir, file_path)
+	}
+
+	absolute, err := filepath.Abs(file_path)
+	if err != nil {
+		return file_path
 	}
 
+	return absolute
+}
+
+func (c *Client) parseConfig(ctx context.Context, config_input string, opts ParseOpts) (config string, err error) {
+
+	config_type := configProtocol(config_input)
+
+	is_yaml := strings.HasPrefix(config_type, "yaml")
+	config_type = strings.TrimPrefix(config_type, "yaml+")
+	config_input = strings.TrimPrefix(config_input, "yaml+")
+
 	var raw []byte
+	var content_type string
 
 	switch config_type {
 	case "json":
 		config = config_input
+	case "yaml":
+		config = strings.TrimPrefix(config_input, "yaml://")
 	case "file":
 		split := strings.Split(config_input, "://")
-		raw, err = os.ReadFile(split[1])
+		raw, err = os.ReadFile(resolvePath(opts.BaseDir, split[1]))
 		if err != nil {
 			return
 		}
 		config = string(raw[:])
+		is_yaml = is_yaml || isYAMLPath(split[1])
 	case "http", "https":
-		raw, err = getHttpContent(config_input)
+		raw, content_type, err = getHttpContent(ctx, config_input, findConfigSource(config_input, opts.Sources))
+		if err != nil {
+			return
+		}
+		config = string(raw[:])
+		is_yaml = is_yaml || isYAMLURL(config_input) || isYAMLContentType(content_type)
+	case "git":
+		var source gitSource
+		source, err = parseGitSource(config_input)
 		if err != nil {
 			return
 		}
+		var revision string
+		raw, revision, err = source.read(opts.BaseDir)
+		if err != nil {
+			return
+		}
+		if opts.Revisions != nil {
+			opts.Revisions[config_input] = revision
+		}
 		config = string(raw[:])
+		is_yaml = is_yaml || isYAMLPath(source.Path)
+	case "env":
+		name := strings.TrimPrefix(config_input, "env://")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			err = fmt.Errorf("environment variable %s is not set", name)
+			return
+		}
+		config = value
 	default:
 		err = fmt.Errorf("unknown protocol used in config")
 		return
 	}
 
+	if document := parseSOPS(config); document != nil {
+		if !opts.Decrypt {
+			err = errors.New("sops encrypted sources are only accepted in sensitive_config, as the plaintext would be stored in state otherwise")
+			return
+		}
+		return document.decrypt(c.SopsAgeKeyFile)
+	}
+
+	if is_yaml {
+		config, err = yamlToJSON(config)
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
 
-func getHttpContent(url string) ([]byte, error) {
-	response, err := http.Get(url)
+// configContentHash returns a SHA-256 hash over the normalized config content,
+// used to detect changes in referenced sources.
+func configContentHash(config, config_override string) string {
+	hash := sha256.New()
+	for i, content := range []string{config, config_override} {
+		// Formatting and key order don't change the content
+		if normalized, err := normalizeJSON(content); err == nil {
+			content = normalized
+		}
+		if i > 0 {
+			hash.Write([]byte{0})
+		}
+		hash.Write([]byte(content))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// validateJSON checks that a parsed config is valid JSON, syntax errors are
+// reported with their line and column.
+func validateJSON(config string) error {
+	var data interface{}
+	err := json.Unmarshal([]byte(config), &data)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, column := 1, 1
+		offset := min(max(syntaxErr.Offset-1, 0), int64(len(config)))
+		for _, char := range config[:offset] {
+			column++
+			if char == '\n' {
+				line++
+				column = 1
+			}
+		}
+		return fmt.Errorf("%s (line %d, column %d)", syntaxErr, line, column)
+	}
+
+	return err
+}
+
+func getHttpContent(ctx context.Context, url string, source *ConfigSource) ([]byte, string, error) {
+	if source == nil {
+		source = &ConfigSource{URL: url, Timeout: defaultConfigSourceTimeout}
+	}
+
+	client, err := source.httpClient()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var data []byte
+	var content_type string
+	for attempt := 0; attempt <= source.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, "", fmt.Errorf("%w, last error: %v", ctx.Err(), err)
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+
+		var retry bool
+		data, content_type, retry, err = fetchHttpContent(ctx, client, url, source)
+		if err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = source.verify(data); err != nil {
+		return nil, "", err
+	}
+
+	return data, content_type, nil
+}
+
+// fetchHttpContent makes a single request, reporting whether a failure is
+// worth retrying.
+func fetchHttpContent(ctx context.Context, client *http.Client, url string, source *ConfigSource) (data []byte, content_type string, retry bool, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("GET error: %v", err)
+	}
+	source.authorize(request)
+
+	response, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("GET error: %v", err)
+		return nil, "", true, fmt.Errorf("GET error: %v", err)
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status error: %v", response.StatusCode)
+		retry = response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+		return nil, "", retry, fmt.Errorf("status error: %v", response.StatusCode)
 	}
 
-	data, err := io.ReadAll(response.Body)
+	data, err = io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read body: %v", err)
+		return nil, "", true, fmt.Errorf("read body: %v", err)
 	}
 
-	return data, nil
+	return data, response.Header.Get("Content-Type"), false, nil
 }
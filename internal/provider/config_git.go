@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// scpLikeURL matches repositories given as `user@host:path`.
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// gitSource is a config source read from a git repository, given as
+// `git::<repo>//<path>?ref=<rev>`. The repository is a local checkout, a local
+// bare repository or a remote URL.
+type gitSource struct {
+	Repository string
+	Path       string
+	Ref        string
+}
+
+func parseGitSource(config_input string) (source gitSource, err error) {
+	input := strings.TrimPrefix(config_input, "git::")
+
+	if i := strings.LastIndex(input, "?"); i >= 0 {
+		query, err := url.ParseQuery(input[i+1:])
+		if err != nil {
+			return source, fmt.Errorf("unable to parse query of git source: %w", err)
+		}
+		source.Ref = query.Get("ref")
+		input = input[:i]
+	}
+	if source.Ref == "" {
+		source.Ref = "HEAD"
+	}
+
+	// The path is separated by a double slash, which is skipped in the scheme
+	// of a remote URL
+	offset := 0
+	if i := strings.Index(input, "://"); i >= 0 {
+		offset = i + len("://")
+	}
+	i := strings.Index(input[offset:], "//")
+	if i < 0 {
+		return source, fmt.Errorf("git source %s has no path, expected git::<repo>//<path>", config_input)
+	}
+
+	source.Repository = input[:offset+i]
+	source.Path = strings.TrimLeft(input[offset+i+2:], "/")
+	if source.Repository == "" || source.Path == "" {
+		return source, fmt.Errorf("git source %s has no path, expected git::<repo>//<path>", config_input)
+	}
+
+	// Both are passed to git as arguments, where they would be taken as options
+	if strings.HasPrefix(source.Repository, "-") {
+		return source, fmt.Errorf("git source %s has a repository starting with -", config_input)
+	}
+	if strings.HasPrefix(source.Ref, "-") {
+		return source, fmt.Errorf("git source %s has a ref starting with -", config_input)
+	}
+
+	return
+}
+
+func (s gitSource) remote() bool {
+	return strings.Contains(s.Repository, "://") || scpLikeURL.MatchString(s.Repository)
+}
+
+// read returns the content of the file at the revision the ref resolves to,
+// together with the revision. Remote repositories are fetched into a
+// temporary bare repository.
+func (s gitSource) read(base_dir string) (content []byte, revision string, err error) {
+	repository := resolvePath(base_dir, s.Repository)
+	ref := s.Ref
+
+	if s.remote() {
+		repository, err = os.MkdirTemp("", "tanka-git-source-")
+		if err != nil {
+			return
+		}
+		defer os.RemoveAll(repository)
+
+		if _, err = runGit(repository, "init", "--quiet", "--bare"); err != nil {
+			return
+		}
+		if _, err = runGit(repository, "fetch", "--quiet", "--depth", "1", "--end-of-options", s.Repository, ref); err != nil {
+			return
+		}
+		ref = "FETCH_HEAD"
+	}
+
+	raw, err := runGit(repository, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return
+	}
+	revision = strings.TrimSpace(string(raw))
+
+	content, err = runGit(repository, "show", revision+":"+s.Path)
+
+	return
+}
+
+func runGit(dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// configRevisionsValue converts the resolved revisions into the
+// config_revisions map, which is null without any git source.
+func configRevisionsValue(resolved resolvedConfig) (types.Map, diag.Diagnostics) {
+	if len(resolved.Revisions) == 0 {
+		return types.MapNull(types.StringType), nil
+	}
+
+	elements := make(map[string]attr.Value, len(resolved.Revisions))
+	for source, revision := range resolved.Revisions {
+		elements[source] = types.StringValue(revision)
+	}
+
+	return types.MapValue(types.StringType, elements)
+}
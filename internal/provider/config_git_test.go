@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected gitSource
+		err      string
+	}{
+		{
+			name:     "local repository",
+			input:    "git::../config//envs/prod.json?ref=v1.2.0",
+			expected: gitSource{Repository: "../config", Path: "envs/prod.json", Ref: "v1.2.0"},
+		},
+		{
+			name:     "default ref",
+			input:    "git::/srv/config//prod.json",
+			expected: gitSource{Repository: "/srv/config", Path: "prod.json", Ref: "HEAD"},
+		},
+		{
+			name:     "https",
+			input:    "git::https://example.com/org/config.git//envs/prod.yaml?ref=main",
+			expected: gitSource{Repository: "https://example.com/org/config.git", Path: "envs/prod.yaml", Ref: "main"},
+		},
+		{
+			name:     "file url",
+			input:    "git::file:///srv/config//prod.json?ref=abc123",
+			expected: gitSource{Repository: "file:///srv/config", Path: "prod.json", Ref: "abc123"},
+		},
+		{
+			name:     "scp-like",
+			input:    "git::git@example.com:org/config.git//prod.json?ref=main",
+			expected: gitSource{Repository: "git@example.com:org/config.git", Path: "prod.json", Ref: "main"},
+		},
+		{
+			name:     "extra slashes before the path",
+			input:    "git::/srv/config///prod.json",
+			expected: gitSource{Repository: "/srv/config", Path: "prod.json", Ref: "HEAD"},
+		},
+		{
+			name:  "no path",
+			input: "git::https://example.com/org/config.git?ref=main",
+			err:   "has no path",
+		},
+		{
+			name:  "empty path",
+			input: "git::/srv/config//",
+			err:   "has no path",
+		},
+		{
+			name:  "ref starting with a dash",
+			input: "git::file:///srv/config//prod.json?ref=--upload-pack=touch%20/tmp/pwned",
+			err:   "ref starting with -",
+		},
+		{
+			name:  "repository starting with a dash",
+			input: "git::--upload-pack=touch@host:repo//prod.json",
+			err:   "repository starting with -",
+		},
+		{
+			name:  "invalid query",
+			input: "git::/srv/config//prod.json?ref=%zz",
+			err:   "unable to parse query",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseGitSource(test.input)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGitSourceRead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repository := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repository
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %s: %s", args[0], err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	git("init", "--quiet")
+	if err := os.WriteFile(filepath.Join(repository, "config.json"), []byte(`{"replicas":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "config.json")
+	git("commit", "--quiet", "-m", "first")
+	git("tag", "v1")
+	first := git("rev-parse", "HEAD")
+
+	if err := os.WriteFile(filepath.Join(repository, "config.json"), []byte(`{"replicas":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "--quiet", "-am", "second")
+	second := git("rev-parse", "HEAD")
+
+	tests := []struct {
+		name     string
+		source   gitSource
+		content  string
+		revision string
+	}{
+		{"local head", gitSource{Repository: repository, Path: "config.json", Ref: "HEAD"}, `{"replicas":2}`, second},
+		{"local tag", gitSource{Repository: repository, Path: "config.json", Ref: "v1"}, `{"replicas":1}`, first},
+		{"remote head", gitSource{Repository: "file://" + repository, Path: "config.json", Ref: "HEAD"}, `{"replicas":2}`, second},
+		{"remote tag", gitSource{Repository: "file://" + repository, Path: "config.json", Ref: "v1"}, `{"replicas":1}`, first},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, revision, err := test.source.read("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.content {
+				t.Errorf("expected content %s, got %s", test.content, content)
+			}
+			if revision != test.revision {
+				t.Errorf("expected revision %s, got %s", test.revision, revision)
+			}
+		})
+	}
+
+	// Options given as the ref are passed after --end-of-options
+	marker := filepath.Join(t.TempDir(), "pwned")
+	source := gitSource{Repository: "file://" + repository, Path: "config.json", Ref: "--upload-pack=touch " + marker + "; git-upload-pack"}
+	if _, _, err := source.read(""); err == nil {
+		t.Error("expected the ref to be rejected by git")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the ref not to be run as upload-pack")
+	}
+}
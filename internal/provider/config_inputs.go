@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// configInputs are the config attributes shared by the release and the data
+// sources evaluating a tanka environment.
+type configInputs struct {
+	Config               ConfigString
+	ConfigOverride       ConfigString
+	ConfigObject         types.Dynamic
+	ConfigOverrideObject types.Dynamic
+	ConfigLayers         types.List
+}
+
+// ConfigInputsModel describes the config attributes, it is embedded in the
+// models of the release and the data sources evaluating a tanka environment.
+type ConfigInputsModel struct {
+	Config               ConfigString  `tfsdk:"config"`
+	ConfigOverride       ConfigString  `tfsdk:"config_override"`
+	ConfigObject         types.Dynamic `tfsdk:"config_object"`
+	ConfigOverrideObject types.Dynamic `tfsdk:"config_override_object"`
+	ConfigLayers         types.List    `tfsdk:"config_layers"`
+}
+
+// configInputs returns the config attributes of the model.
+func (m ConfigInputsModel) configInputs() configInputs {
+	return configInputs(m)
+}
+
+// unknown reports whether any of the inputs is not known yet.
+func (i configInputs) unknown() bool {
+	return i.Config.IsUnknown() || i.ConfigOverride.IsUnknown() || configLayersUnknown(i.ConfigLayers) ||
+		dynamicUnknown(i.ConfigObject) || dynamicUnknown(i.ConfigOverrideObject)
+}
+
+// parseOpts collects the settings used to resolve config inputs. The base
+// directory falls back to the one of the provider.
+func (c *Client) parseOpts(ctx context.Context, base_dir types.String, models []ConfigSourceModel) (opts ParseOpts, diags diag.Diagnostics) {
+	opts.Sources, diags = toConfigSources(ctx, models)
+
+	opts.BaseDir = c.BaseDir
+	if !base_dir.IsNull() {
+		opts.BaseDir = base_dir.ValueString()
+	}
+
+	return
+}
+
+// resolveConfig parses a config input and validates the resulting JSON,
+// reporting any problem on the given attribute.
+func (c *Client) resolveConfig(ctx context.Context, attribute path.Path, config_input string, opts ParseOpts) (config string, diags diag.Diagnostics) {
+	config, err := c.parseConfig(ctx, config_input, opts)
+	if err != nil {
+		diags.AddAttributeError(attribute, "Parse Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+		return
+	}
+
+	if err = validateJSON(config); err != nil {
+		diags.AddAttributeError(attribute, "Invalid JSON", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	}
+
+	return
+}
+
+// resolveInputs resolves the config inputs and merges them.
+// With config_layers the merged layers are injected as the config, otherwise
+// config and config_override are injected as they are.
+func (c *Client) resolveInputs(ctx context.Context, data configInputs, opts ParseOpts) (resolved resolvedConfig, diags diag.Diagnostics) {
+	var layers []string
+
+	opts.Revisions = map[string]string{}
+	resolved.Revisions = opts.Revisions
+
+	if data.ConfigLayers.IsNull() {
+		var d diag.Diagnostics
+		if data.ConfigObject.IsNull() {
+			resolved.Config, d = c.resolveConfig(ctx, path.Root("config"), stringOrEmptyObject(data.Config), opts)
+		} else {
+			resolved.Config, d = resolveConfigObject(path.Root("config_object"), data.ConfigObject)
+		}
+		diags.Append(d...)
+
+		if data.ConfigOverrideObject.IsNull() {
+			resolved.ConfigOverride, d = c.resolveConfig(ctx, path.Root("config_override"), stringOrEmptyObject(data.ConfigOverride), opts)
+		} else {
+			resolved.ConfigOverride, d = resolveConfigObject(path.Root("config_override_object"), data.ConfigOverrideObject)
+		}
+		diags.Append(d...)
+
+		layers = []string{resolved.Config, resolved.ConfigOverride}
+	} else {
+		var inputs []ConfigString
+		diags.Append(data.ConfigLayers.ElementsAs(ctx, &inputs, false)...)
+		if diags.HasError() {
+			return
+		}
+
+		for i, input := range inputs {
+			// Skipped layers keep their index, so it matches the list
+			layer := "{}"
+			if !input.IsNull() {
+				var d diag.Diagnostics
+				layer, d = c.resolveConfig(ctx, path.Root("config_layers").AtListIndex(i), input.ValueString(), opts)
+				diags.Append(d...)
+			}
+			layers = append(layers, layer)
+		}
+	}
+
+	if diags.HasError() {
+		return
+	}
+
+	effective, contributions, err := mergeConfigLayers(layers)
+	if err != nil {
+		diags.AddError("Merge Error", fmt.Sprintf("Unable to merge config layers, got error: %s", err))
+		return
+	}
+	resolved.Effective = effective
+	resolved.Layers = contributions
+
+	if !data.ConfigLayers.IsNull() {
+		resolved.Config = effective
+		resolved.ConfigOverride = "{}"
+	}
+
+	return
+}
+
+// resolveConfigObject converts a native config object into JSON, reporting any
+// problem on the given attribute.
+func resolveConfigObject(attribute path.Path, object types.Dynamic) (config string, diags diag.Diagnostics) {
+	config, err := dynamicToJSON(object)
+	if err != nil {
+		diags.AddAttributeError(attribute, "Marshal Error", fmt.Sprintf("Unable to convert the value to json, got error: %s", err))
+	}
+
+	return
+}
+
+// validateConfigInputs checks the config inputs for conflicts and validates
+// inline data. Further config attributes to validate are given by name.
+func validateConfigInputs(ctx context.Context, config tfsdk.Config, attributes ...string) (diags diag.Diagnostics) {
+	var models []ConfigSourceModel
+	diags.Append(config.GetAttribute(ctx, path.Root("config_source"), &models)...)
+	if diags.HasError() {
+		return
+	}
+
+	_, source_diags := toConfigSources(ctx, models)
+	diags.Append(source_diags...)
+
+	var layers types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("config_layers"), &layers)...)
+	if diags.HasError() {
+		return
+	}
+
+	inputs := map[string]path.Path{}
+	for _, attribute := range append([]string{"config", "config_override"}, attributes...) {
+		inputs[attribute] = path.Root(attribute)
+	}
+
+	objects := map[string]string{"config_object": "config", "config_override_object": "config_override"}
+	for object_attribute, attribute := range objects {
+		var object types.Dynamic
+		diags.Append(config.GetAttribute(ctx, path.Root(object_attribute), &object)...)
+		if object.IsNull() {
+			continue
+		}
+
+		var value ConfigString
+		diags.Append(config.GetAttribute(ctx, path.Root(attribute), &value)...)
+		if !value.IsNull() {
+			diags.AddAttributeError(path.Root(object_attribute), "Conflicting Configuration", fmt.Sprintf("`%s` can't be set together with `%s`.", object_attribute, attribute))
+		}
+
+		if !dynamicUnknown(object) {
+			if _, err := dynamicToJSON(object); err != nil {
+				diags.AddAttributeError(path.Root(object_attribute), "Invalid Object", fmt.Sprintf("Unable to convert the value to json, got error: %s", err))
+			}
+		}
+	}
+
+	if !layers.IsNull() {
+		for object_attribute, attribute := range objects {
+			var value ConfigString
+			var object types.Dynamic
+			diags.Append(config.GetAttribute(ctx, path.Root(attribute), &value)...)
+			diags.Append(config.GetAttribute(ctx, path.Root(object_attribute), &object)...)
+
+			if !value.IsNull() {
+				diags.AddAttributeError(path.Root(attribute), "Conflicting Configuration", fmt.Sprintf("`%s` can't be set together with `config_layers`.", attribute))
+			}
+			if !object.IsNull() {
+				diags.AddAttributeError(path.Root(object_attribute), "Conflicting Configuration", fmt.Sprintf("`%s` can't be set together with `config_layers`.", object_attribute))
+			}
+		}
+
+		if !layers.IsUnknown() {
+			for i := range layers.Elements() {
+				attribute := fmt.Sprintf("config_layers.%d", i)
+				inputs[attribute] = path.Root("config_layers").AtListIndex(i)
+			}
+		}
+	}
+
+	// Only inline data can be checked without the provider being configured,
+	// sources are resolved and checked during plan
+	for _, attribute := range inputs {
+		var value ConfigString
+		diags.Append(config.GetAttribute(ctx, attribute, &value)...)
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+
+		protocol := configProtocol(value.ValueString())
+		if protocol != "json" && protocol != "yaml" {
+			continue
+		}
+
+		parsed, err := parseInlineConfig(value.ValueString())
+		if err != nil {
+			diags.AddAttributeError(attribute, "Parse Error", fmt.Sprintf("Unable to parse yaml data, got error: %s", err))
+			continue
+		}
+
+		if err := validateJSON(parsed); err != nil {
+			diags.AddAttributeError(attribute, "Invalid JSON", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+		}
+	}
+
+	return
+}
+
+// stringOrEmptyObject returns the config input, or the empty object when it
+// isn't set.
+func stringOrEmptyObject(value ConfigString) string {
+	if value.IsNull() {
+		return "{}"
+	}
+
+	return value.ValueString()
+}
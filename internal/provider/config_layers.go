@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// effectiveConfigAttrTypes describes the effective_config object.
+var effectiveConfigAttrTypes = map[string]attr.Type{
+	"json":   types.StringType,
+	"layers": types.MapType{ElemType: types.Int64Type},
+}
+
+// resolvedConfig holds the config of a release after all sources were
+// resolved, as it is injected into the tanka package.
+type resolvedConfig struct {
+	Config         string
+	ConfigOverride string
+
+	// Effective is the merge of all layers in JSON
+	Effective string
+	// Layers maps each top-level key to the index of the layer it was last
+	// set in
+	Layers map[string]int64
+	// Revisions maps each git source to the commit it resolved to
+	Revisions map[string]string
+}
+
+// decodeJSON unmarshals JSON while keeping numbers as they are written, so
+// large integers survive a round trip.
+func decodeJSON(data string) (value interface{}, err error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+
+	return
+}
+
+// mergeConfigLayers merges the layers in order with `std.mergePatch`
+// semantics and records which layer contributed each top-level key.
+func mergeConfigLayers(layers []string) (merged string, contributions map[string]int64, err error) {
+	var result interface{} = map[string]interface{}{}
+	contributions = map[string]int64{}
+
+	for i, layer := range layers {
+		patch, err := decodeJSON(layer)
+		if err != nil {
+			return "", nil, err
+		}
+
+		patch_object, ok := patch.(map[string]interface{})
+		if !ok {
+			// A layer that isn't an object replaces everything before it
+			contributions = map[string]int64{}
+		}
+		for key, value := range patch_object {
+			if value == nil {
+				delete(contributions, key)
+				continue
+			}
+			contributions[key] = int64(i)
+		}
+
+		result = mergePatch(result, patch)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(raw), contributions, nil
+}
+
+// effectiveConfigValue converts the merge result into the effective_config
+// object.
+func effectiveConfigValue(resolved resolvedConfig) (types.Object, diag.Diagnostics) {
+	elements := make(map[string]attr.Value, len(resolved.Layers))
+	for key, index := range resolved.Layers {
+		elements[key] = types.Int64Value(index)
+	}
+
+	layers, diags := types.MapValue(types.Int64Type, elements)
+	if diags.HasError() {
+		return types.ObjectNull(effectiveConfigAttrTypes), diags
+	}
+
+	return types.ObjectValue(effectiveConfigAttrTypes, map[string]attr.Value{
+		"json":   types.StringValue(resolved.Effective),
+		"layers": layers,
+	})
+}
+
+// configLayersUnknown reports whether the list or any of its layers is
+// unknown.
+func configLayersUnknown(layers types.List) bool {
+	if layers.IsUnknown() {
+		return true
+	}
+
+	for _, layer := range layers.Elements() {
+		if layer.IsUnknown() {
+			return true
+		}
+	}
+
+	return false
+}
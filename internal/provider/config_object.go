@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// dynamicToJSON converts the HCL object of a dynamic config attribute into
+// JSON. Only objects and maps are accepted, as the result is injected as a
+// config object.
+func dynamicToJSON(value types.Dynamic) (string, error) {
+	if value.IsUnknown() || value.IsUnderlyingValueUnknown() {
+		return "", fmt.Errorf("value is not known yet")
+	}
+
+	switch value.UnderlyingValue().(type) {
+	case basetypes.ObjectValue, basetypes.MapValue:
+	default:
+		return "", fmt.Errorf("expected an object, got %s", attrKind(value.UnderlyingValue()))
+	}
+
+	object, err := attrToInterface(value.UnderlyingValue())
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// attrToInterface converts a framework value into the Go value that encodes
+// to the same JSON as `jsonencode()` would produce.
+func attrToInterface(value attr.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("value is not known yet")
+	}
+
+	switch value := value.(type) {
+	case basetypes.DynamicValue:
+		return attrToInterface(value.UnderlyingValue())
+	case basetypes.StringValue:
+		return value.ValueString(), nil
+	case basetypes.BoolValue:
+		return value.ValueBool(), nil
+	case basetypes.NumberValue:
+		return json.Number(value.ValueBigFloat().Text('g', -1)), nil
+	case basetypes.Int64Value:
+		return value.ValueInt64(), nil
+	case basetypes.Float64Value:
+		return value.ValueFloat64(), nil
+	case basetypes.ObjectValue:
+		return attrMapToInterface(value.Attributes())
+	case basetypes.MapValue:
+		return attrMapToInterface(value.Elements())
+	case basetypes.ListValue:
+		return attrListToInterface(value.Elements())
+	case basetypes.TupleValue:
+		return attrListToInterface(value.Elements())
+	case basetypes.SetValue:
+		return attrListToInterface(value.Elements())
+	}
+
+	return nil, fmt.Errorf("unsupported value of type %T", value)
+}
+
+func attrMapToInterface(elements map[string]attr.Value) (interface{}, error) {
+	result := make(map[string]interface{}, len(elements))
+	for key, element := range elements {
+		value, err := attrToInterface(element)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func attrListToInterface(elements []attr.Value) (interface{}, error) {
+	result := make([]interface{}, 0, len(elements))
+	for i, element := range elements {
+		value, err := attrToInterface(element)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// interfaceToAttr converts a decoded JSON value into the framework value
+// `jsondecode()` would produce, objects become objects and arrays tuples.
+func interfaceToAttr(value interface{}) (attr.Value, error) {
+	switch value := value.(type) {
+	case nil:
+		return types.DynamicNull(), nil
+	case string:
+		return types.StringValue(value), nil
+	case bool:
+		return types.BoolValue(value), nil
+	case json.Number:
+		number, _, err := big.ParseFloat(value.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return nil, err
+		}
+		return types.NumberValue(number), nil
+	case float64:
+		return types.NumberValue(big.NewFloat(value)), nil
+	case int64:
+		return types.NumberValue(new(big.Float).SetInt64(value)), nil
+	case map[string]interface{}:
+		attr_types := make(map[string]attr.Type, len(value))
+		attributes := make(map[string]attr.Value, len(value))
+		for key, element := range value {
+			converted, err := interfaceToAttr(element)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			attr_types[key] = converted.Type(context.Background())
+			attributes[key] = converted
+		}
+		object, diags := types.ObjectValue(attr_types, attributes)
+		if diags.HasError() {
+			return nil, fmt.Errorf("unable to build object: %s", diags.Errors()[0].Detail())
+		}
+		return object, nil
+	case []interface{}:
+		element_types := make([]attr.Type, 0, len(value))
+		elements := make([]attr.Value, 0, len(value))
+		for i, element := range value {
+			converted, err := interfaceToAttr(element)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			element_types = append(element_types, converted.Type(context.Background()))
+			elements = append(elements, converted)
+		}
+		tuple, diags := types.TupleValue(element_types, elements)
+		if diags.HasError() {
+			return nil, fmt.Errorf("unable to build tuple: %s", diags.Errors()[0].Detail())
+		}
+		return tuple, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value of type %T", value)
+}
+
+func attrKind(value attr.Value) string {
+	switch value.(type) {
+	case basetypes.StringValue:
+		return "a string"
+	case basetypes.BoolValue:
+		return "a bool"
+	case basetypes.NumberValue, basetypes.Int64Value, basetypes.Float64Value:
+		return "a number"
+	case basetypes.ListValue, basetypes.TupleValue, basetypes.SetValue:
+		return "a list"
+	case nil:
+		return "null"
+	}
+
+	return "an unsupported value"
+}
+
+// dynamicUnknown reports whether the value or anything nested in it is
+// unknown.
+func dynamicUnknown(value types.Dynamic) bool {
+	if value.IsUnknown() || value.IsUnderlyingValueUnknown() {
+		return true
+	}
+	if value.IsNull() || value.IsUnderlyingValueNull() {
+		return false
+	}
+
+	return attrUnknown(value.UnderlyingValue())
+}
+
+func attrUnknown(value attr.Value) bool {
+	if value.IsUnknown() {
+		return true
+	}
+
+	var elements []attr.Value
+	switch value := value.(type) {
+	case basetypes.DynamicValue:
+		return dynamicUnknown(value)
+	case basetypes.ObjectValue:
+		for _, element := range value.Attributes() {
+			elements = append(elements, element)
+		}
+	case basetypes.MapValue:
+		for _, element := range value.Elements() {
+			elements = append(elements, element)
+		}
+	case basetypes.ListValue:
+		elements = value.Elements()
+	case basetypes.TupleValue:
+		elements = value.Elements()
+	case basetypes.SetValue:
+		elements = value.Elements()
+	}
+
+	for _, element := range elements {
+		if attrUnknown(element) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// configSchemaViolation is a single JSON Schema violation, located by the
+// JSON pointer of the offending value.
+type configSchemaViolation struct {
+	Pointer string
+	Message string
+}
+
+// validateConfigSchema merges the config and config override the same way
+// `std.mergePatch` does in Jsonnet, and validates the result against the
+// given JSON Schema.
+func validateConfigSchema(config_schema, config, config_override string) (violations []configSchemaViolation, err error) {
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource("config_schema.json", strings.NewReader(config_schema)); err != nil {
+		return nil, fmt.Errorf("loading schema: %w", err)
+	}
+
+	schema, err := compiler.Compile("config_schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("compiling schema: %w", err)
+	}
+
+	var target, patch interface{}
+	if err = json.Unmarshal([]byte(config), &target); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(config_override), &patch); err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(mergePatch(target, patch))
+	if validationErr, ok := err.(*jsonschema.ValidationError); ok {
+		violations = collectViolations(validationErr)
+		sort.SliceStable(violations, func(i, j int) bool { return violations[i].Pointer < violations[j].Pointer })
+		return violations, nil
+	}
+
+	return nil, err
+}
+
+// collectViolations flattens the validation error tree into its leaves, which
+// carry the actual violations.
+func collectViolations(err *jsonschema.ValidationError) (violations []configSchemaViolation) {
+	if len(err.Causes) == 0 {
+		pointer := err.InstanceLocation
+		if pointer == "" {
+			pointer = "/"
+		}
+		return []configSchemaViolation{{Pointer: pointer, Message: err.Message}}
+	}
+
+	for _, cause := range err.Causes {
+		violations = append(violations, collectViolations(cause)...)
+	}
+
+	return
+}
+
+// configSchemaAttribute returns the config attribute a violation is reported
+// on, which is the input that last set the top-level key of its pointer.
+// Violations of keys no input sets go on the config itself.
+func configSchemaAttribute(data configInputs, layers map[string]int64, pointer string) path.Path {
+	key, _, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	index, ok := layers[key]
+
+	if !data.ConfigLayers.IsNull() {
+		if !ok {
+			return path.Root("config_layers")
+		}
+		return path.Root("config_layers").AtListIndex(int(index))
+	}
+
+	// Without layers, the config is the first and the override the second
+	if ok && index == 1 {
+		if !data.ConfigOverrideObject.IsNull() {
+			return path.Root("config_override_object")
+		}
+		return path.Root("config_override")
+	}
+	if !data.ConfigObject.IsNull() {
+		return path.Root("config_object")
+	}
+	return path.Root("config")
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestConfigSchemaAttribute(t *testing.T) {
+	inputs := configInputs{
+		ConfigObject:         types.DynamicNull(),
+		ConfigOverrideObject: types.DynamicNull(),
+		ConfigLayers:         types.ListNull(ConfigStringType{}),
+	}
+	objects := inputs
+	objects.ConfigObject = types.DynamicValue(types.StringValue("{}"))
+	objects.ConfigOverrideObject = types.DynamicValue(types.StringValue("{}"))
+	layered := inputs
+	layered.ConfigLayers = types.ListValueMust(ConfigStringType{}, []attr.Value{})
+
+	tests := []struct {
+		name      string
+		data      configInputs
+		pointer   string
+		attribute path.Path
+	}{
+		{name: "config", data: inputs, pointer: "/replicas", attribute: path.Root("config")},
+		{name: "config override", data: inputs, pointer: "/image/tag", attribute: path.Root("config_override")},
+		{name: "missing key", data: inputs, pointer: "/", attribute: path.Root("config")},
+		{name: "config object", data: objects, pointer: "/replicas", attribute: path.Root("config_object")},
+		{name: "config override object", data: objects, pointer: "/image", attribute: path.Root("config_override_object")},
+		{name: "layer", data: layered, pointer: "/image/tag", attribute: path.Root("config_layers").AtListIndex(1)},
+		{name: "escaped key", data: layered, pointer: "/a~1b", attribute: path.Root("config_layers").AtListIndex(2)},
+		{name: "missing key in layers", data: layered, pointer: "/name", attribute: path.Root("config_layers")},
+	}
+
+	layers := map[string]int64{"replicas": 0, "image": 1, "a/b": 2}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribute := configSchemaAttribute(test.data, layers, test.pointer)
+			if !attribute.Equal(test.attribute) {
+				t.Errorf("expected %s, got %s", test.attribute, attribute)
+			}
+		})
+	}
+}
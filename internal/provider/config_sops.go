@@ -0,0 +1,399 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// sopsEncryptedValue matches a value encrypted by SOPS.
+var sopsEncryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// sopsMACOnlyEncryptedInitialization is written to the message authentication
+// code first when only encrypted values are authenticated, the same bytes as
+// SOPS uses.
+var sopsMACOnlyEncryptedInitialization = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+// sopsMetadata is the part of the `sops` key needed to decrypt a document
+// with age.
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	LastModified            string `yaml:"lastmodified"`
+	MAC                     string `yaml:"mac"`
+	MACOnlyEncrypted        bool   `yaml:"mac_only_encrypted"`
+	UnencryptedSuffix       string `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string `yaml:"unencrypted_regex"`
+	EncryptedRegex          string `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string `yaml:"encrypted_comment_regex"`
+}
+
+// sopsDocument is a parsed SOPS document, JSON is parsed as YAML as well.
+type sopsDocument struct {
+	root     *yamlv3.Node
+	metadata *sopsMetadata
+
+	unencrypted_regex *regexp.Regexp
+	encrypted_regex   *regexp.Regexp
+}
+
+// sopsComment is the decrypted value of a comment SOPS stored as list item.
+// Comments are left out of the result and the message authentication code.
+type sopsComment struct{}
+
+// parseSOPS returns the document when the config is encrypted by SOPS, and
+// nil otherwise.
+func parseSOPS(config string) *sopsDocument {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(config), &document); err != nil {
+		return nil
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "sops" {
+			continue
+		}
+
+		var metadata sopsMetadata
+		if err := root.Content[i+1].Decode(&metadata); err != nil || metadata.MAC == "" {
+			return nil
+		}
+		return &sopsDocument{root: root, metadata: &metadata}
+	}
+
+	return nil
+}
+
+// sopsAgeKeyFile returns the age key file configured for the provider,
+// falling back to the locations SOPS uses.
+func sopsAgeKeyFile(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if key_file := os.Getenv("SOPS_AGE_KEY_FILE"); key_file != "" {
+		return key_file
+	}
+
+	config_dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(config_dir, "sops", "age", "keys.txt")
+}
+
+// loadAgeIdentities reads the age identities from the key file, and from the
+// SOPS_AGE_KEY environment variable when set.
+func loadAgeIdentities(key_file string) (identities []age.Identity, err error) {
+	if key := os.Getenv("SOPS_AGE_KEY"); key != "" {
+		parsed, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse SOPS_AGE_KEY: %w", err)
+		}
+		identities = append(identities, parsed...)
+	}
+
+	raw, err := os.ReadFile(key_file)
+	if err != nil {
+		if len(identities) > 0 && errors.Is(err, os.ErrNotExist) {
+			return identities, nil
+		}
+		return nil, fmt.Errorf("unable to read age key file: %w", err)
+	}
+
+	parsed, err := age.ParseIdentities(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse age key file %s: %w", key_file, err)
+	}
+
+	return append(identities, parsed...), nil
+}
+
+// decrypt decrypts the document with the given age key file and returns the
+// plaintext in JSON. The message authentication code is verified, so values
+// that were tampered with are rejected.
+func (d *sopsDocument) decrypt(key_file string) (string, error) {
+	if len(d.metadata.Age) == 0 {
+		return "", errors.New("sops document has no age recipients, only age keys are supported")
+	}
+
+	// Which values these encrypt depends on the comments of the document,
+	// which aren't kept when it is parsed
+	if d.metadata.UnencryptedCommentRegex != "" || d.metadata.EncryptedCommentRegex != "" {
+		return "", errors.New("sops documents encrypted with unencrypted_comment_regex or encrypted_comment_regex are not supported")
+	}
+
+	var err error
+	if d.metadata.UnencryptedRegex != "" {
+		if d.unencrypted_regex, err = regexp.Compile(d.metadata.UnencryptedRegex); err != nil {
+			return "", fmt.Errorf("unable to parse unencrypted_regex of sops document: %w", err)
+		}
+	}
+	if d.metadata.EncryptedRegex != "" {
+		if d.encrypted_regex, err = regexp.Compile(d.metadata.EncryptedRegex); err != nil {
+			return "", fmt.Errorf("unable to parse encrypted_regex of sops document: %w", err)
+		}
+	}
+
+	identities, err := loadAgeIdentities(sopsAgeKeyFile(key_file))
+	if err != nil {
+		return "", err
+	}
+
+	var data_key []byte
+	for _, recipient := range d.metadata.Age {
+		reader, err := age.Decrypt(armor.NewReader(strings.NewReader(recipient.Enc)), identities...)
+		if err != nil {
+			continue
+		}
+		if data_key, err = io.ReadAll(reader); err == nil {
+			break
+		}
+	}
+	if data_key == nil {
+		return "", errors.New("unable to decrypt the sops data key, none of the age identities matches a recipient of the document")
+	}
+
+	mac := sha512.New()
+	if d.metadata.MACOnlyEncrypted {
+		mac.Write(sopsMACOnlyEncryptedInitialization)
+	}
+
+	result := map[string]interface{}{}
+	for i := 0; i+1 < len(d.root.Content); i += 2 {
+		key := d.root.Content[i].Value
+		if key == "sops" {
+			continue
+		}
+
+		value, err := d.decryptNode(d.root.Content[i+1], []string{key}, data_key, mac)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := value.(sopsComment); !ok {
+			result[key] = value
+		}
+	}
+
+	if err = d.verifyMAC(data_key, mac); err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// decryptNode walks the document in order, the same way SOPS does when it
+// computes the message authentication code. List items share the path of
+// the list.
+func (d *sopsDocument) decryptNode(node *yamlv3.Node, path []string, data_key []byte, mac hash.Hash) (interface{}, error) {
+	switch node.Kind {
+	case yamlv3.AliasNode:
+		return d.decryptNode(node.Alias, path, data_key, mac)
+	case yamlv3.MappingNode:
+		result := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			value, err := d.decryptNode(node.Content[i+1], append(path[:len(path):len(path)], key), data_key, mac)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := value.(sopsComment); !ok {
+				result[key] = value
+			}
+		}
+		return result, nil
+	case yamlv3.SequenceNode:
+		result := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := d.decryptNode(item, path, data_key, mac)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := value.(sopsComment); !ok {
+				result = append(result, value)
+			}
+		}
+		return result, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	encrypted := d.encrypted(path)
+	if encrypted {
+		text, ok := value.(string)
+		if !ok || !sopsEncryptedValue.MatchString(text) {
+			return nil, fmt.Errorf("value of %s is not encrypted, but the sops metadata requires it", strings.Join(path, "."))
+		}
+
+		decrypted, err := sopsDecryptValue(text, data_key, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+		value = decrypted
+	}
+
+	if _, ok := value.(sopsComment); !ok && (encrypted || !d.metadata.MACOnlyEncrypted) {
+		mac.Write(sopsValueBytes(value))
+	}
+
+	return value, nil
+}
+
+// encrypted reports whether SOPS encrypts the value at the path, following
+// the suffix and regex rules of the metadata in the order SOPS applies them.
+func (d *sopsDocument) encrypted(path []string) bool {
+	anyKey := func(match func(key string) bool) bool {
+		for _, key := range path {
+			if match(key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	encrypted := true
+	if suffix := d.metadata.UnencryptedSuffix; suffix != "" && anyKey(func(key string) bool { return strings.HasSuffix(key, suffix) }) {
+		encrypted = false
+	}
+	if suffix := d.metadata.EncryptedSuffix; suffix != "" {
+		encrypted = anyKey(func(key string) bool { return strings.HasSuffix(key, suffix) })
+	}
+	if d.unencrypted_regex != nil && anyKey(d.unencrypted_regex.MatchString) {
+		encrypted = false
+	}
+	if d.encrypted_regex != nil {
+		encrypted = anyKey(d.encrypted_regex.MatchString)
+	}
+
+	return encrypted
+}
+
+func (d *sopsDocument) verifyMAC(data_key []byte, mac hash.Hash) error {
+	// The code is authenticated with the modification time as formatted by SOPS
+	last_modified := d.metadata.LastModified
+	if parsed, err := time.Parse(time.RFC3339, last_modified); err == nil {
+		last_modified = parsed.Format(time.RFC3339)
+	}
+
+	expected, err := sopsDecryptValue(d.metadata.MAC, data_key, last_modified)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt the sops message authentication code: %w", err)
+	}
+
+	if computed := fmt.Sprintf("%X", mac.Sum(nil)); expected != computed {
+		return errors.New("sops message authentication code mismatch, the document was modified after it was encrypted")
+	}
+
+	return nil
+}
+
+// sopsDecryptValue decrypts a single `ENC[AES256_GCM,...]` value, the
+// additional data binds it to its location in the document.
+func sopsDecryptValue(encrypted string, data_key []byte, additional_data string) (interface{}, error) {
+	match := sopsEncryptedValue.FindStringSubmatch(encrypted)
+	if match == nil {
+		return nil, errors.New("value is not encrypted by sops")
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(data_key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additional_data))
+	if err != nil {
+		return nil, err
+	}
+
+	switch match[4] {
+	case "str":
+		return string(plaintext), nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	case "bytes":
+		return string(plaintext), nil
+	case "time":
+		return time.Parse(time.RFC3339, string(plaintext))
+	case "comment":
+		return sopsComment{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported sops value type %s", match[4])
+}
+
+// sopsValueBytes formats a value the way SOPS does for the message
+// authentication code.
+func sopsValueBytes(value interface{}) []byte {
+	switch value := value.(type) {
+	case string:
+		return []byte(value)
+	case int:
+		return []byte(strconv.Itoa(value))
+	case float64:
+		return []byte(strconv.FormatFloat(value, 'f', -1, 64))
+	case bool:
+		if value {
+			return []byte("True")
+		}
+		return []byte("False")
+	case time.Time:
+		raw, _ := value.MarshalText()
+		return raw
+	}
+
+	return []byte(fmt.Sprint(value))
+}
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The fixtures in testdata/sops are encrypted by the sops CLI (3.13.3) with
+// the age key in age_key.txt, e.g.
+//
+//	sops encrypt --age <recipient> config.yaml > config.enc.yaml
+//	sops encrypt --age <recipient> --encrypted-regex '_secret$' regex.yaml > regex.enc.yaml
+//	sops encrypt --age <recipient> --unencrypted-suffix _unencrypted suffix.yaml > suffix.enc.yaml
+//	sops encrypt --age <recipient> --unencrypted-regex '^(port|hosts)$' config.yaml > unencrypted_regex.enc.yaml
+//	sops encrypt --age <recipient> --encrypted-suffix _secret regex.yaml > encrypted_suffix.enc.yaml
+//
+// mac_only.enc.yaml is encrypted from regex.yaml with a creation rule setting
+// `encrypted_regex: _secret$` and `mac_only_encrypted: true`.
+
+const sopsTestKeyFile = "testdata/sops/age_key.txt"
+
+func readSOPSFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", "sops", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(raw)
+}
+
+// decodeSOPSResult decodes JSON with numbers as float64, so the decrypted
+// documents compare equal to their plaintext regardless of number notation.
+func decodeSOPSResult(t *testing.T, config string) interface{} {
+	t.Helper()
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(config), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func TestSOPSDecrypt(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", "")
+
+	tests := []struct {
+		name      string
+		encrypted string
+		plaintext string
+	}{
+		{"yaml with comments", "config.enc.yaml", "config.yaml"},
+		{"json", "config.enc.json", "config.json"},
+		{"encrypted_regex", "regex.enc.yaml", "regex.yaml"},
+		{"mac_only_encrypted", "mac_only.enc.yaml", "regex.yaml"},
+		{"unencrypted_suffix", "suffix.enc.yaml", "suffix.yaml"},
+		{"unencrypted_regex", "unencrypted_regex.enc.yaml", "config.yaml"},
+		{"encrypted_suffix", "encrypted_suffix.enc.yaml", "regex.yaml"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document := parseSOPS(readSOPSFixture(t, test.encrypted))
+			if document == nil {
+				t.Fatal("expected the fixture to be detected as sops document")
+			}
+
+			decrypted, err := document.decrypt(sopsTestKeyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected, err := yamlToJSON(readSOPSFixture(t, test.plaintext))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual, expected := decodeSOPSResult(t, decrypted), decodeSOPSResult(t, expected); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestSOPSDecryptModified(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", "")
+
+	tests := []struct {
+		name      string
+		encrypted string
+		old       string
+		new       string
+		err       string
+	}{
+		{
+			name:      "unencrypted value",
+			encrypted: "regex.enc.yaml",
+			old:       "port: 5432",
+			new:       "port: 5433",
+			err:       "message authentication code mismatch",
+		},
+		{
+			name:      "unencrypted suffix",
+			encrypted: "suffix.enc.yaml",
+			old:       "port_unencrypted: 5432",
+			new:       "port_unencrypted: 5433",
+			err:       "message authentication code mismatch",
+		},
+		{
+			name:      "moved value",
+			encrypted: "config.enc.yaml",
+			old:       "    password: ",
+			new:       "    password_moved: ",
+			err:       "unable to decrypt database.password_moved",
+		},
+		{
+			name:      "plaintext for an encrypted key",
+			encrypted: "regex.enc.yaml",
+			old:       "name: a",
+			new:       "name_secret: a",
+			err:       "database.nested.name_secret is not encrypted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := readSOPSFixture(t, test.encrypted)
+			if !strings.Contains(config, test.old) {
+				t.Fatalf("fixture %s doesn't contain %q", test.encrypted, test.old)
+			}
+
+			document := parseSOPS(strings.Replace(config, test.old, test.new, 1))
+			if document == nil {
+				t.Fatal("expected the fixture to be detected as sops document")
+			}
+
+			_, err := document.decrypt(sopsTestKeyFile)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+
+	// Values outside of the MAC can be changed
+	config := strings.Replace(readSOPSFixture(t, "mac_only.enc.yaml"), "port: 5432", "port: 5433", 1)
+	decrypted, err := parseSOPS(config).decrypt(sopsTestKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(decrypted, `"port":5433`) {
+		t.Errorf("expected the modified port, got %s", decrypted)
+	}
+}
+
+func TestSOPSDecryptWrongKey(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", "")
+
+	key_file := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(key_file, []byte("AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := parseSOPS(readSOPSFixture(t, "config.enc.yaml")).decrypt(key_file)
+	if err == nil || !strings.Contains(err.Error(), "none of the age identities matches") {
+		t.Fatalf("expected a key mismatch, got %v", err)
+	}
+}
+
+func TestParseSOPS(t *testing.T) {
+	for _, config := range []string{`{"a": 1}`, "a: 1\n", `{"sops": "not metadata"}`, "- a\n", "not: [valid"} {
+		if parseSOPS(config) != nil {
+			t.Errorf("expected %q not to be detected as sops document", config)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const defaultConfigSourceTimeout = 30 * time.Second
+
+// ConfigSource holds the settings used when fetching a remote config source.
+type ConfigSource struct {
+	URL           string
+	BearerToken   string
+	Username      string
+	Password      string
+	Headers       map[string]string
+	CACertificate string
+	Timeout       time.Duration
+	Retries       int
+	SHA256        string
+}
+
+// ConfigSourceModel describes the config_source block data model.
+type ConfigSourceModel struct {
+	Url           types.String `tfsdk:"url"`
+	BearerToken   types.String `tfsdk:"bearer_token"`
+	Username      types.String `tfsdk:"username"`
+	Password      types.String `tfsdk:"password"`
+	Headers       types.Map    `tfsdk:"headers"`
+	CaCertificate types.String `tfsdk:"ca_certificate"`
+	Timeout       types.String `tfsdk:"timeout"`
+	Retries       types.Int64  `tfsdk:"retries"`
+	Sha256        types.String `tfsdk:"sha256"`
+}
+
+// toConfigSources converts the config_source blocks, validating the values
+// that can't be expressed in the schema.
+func toConfigSources(ctx context.Context, models []ConfigSourceModel) (sources []ConfigSource, diags diag.Diagnostics) {
+	for i, model := range models {
+		attribute := path.Root("config_source").AtListIndex(i)
+
+		source := ConfigSource{
+			URL:           model.Url.ValueString(),
+			BearerToken:   model.BearerToken.ValueString(),
+			Username:      model.Username.ValueString(),
+			Password:      model.Password.ValueString(),
+			CACertificate: model.CaCertificate.ValueString(),
+			Timeout:       defaultConfigSourceTimeout,
+			Retries:       int(model.Retries.ValueInt64()),
+			SHA256:        strings.ToLower(model.Sha256.ValueString()),
+		}
+
+		if source.BearerToken != "" && source.Username != "" {
+			diags.AddAttributeError(attribute, "Conflicting Authentication", "Only one of `bearer_token` and `username` can be set.")
+		}
+
+		if !model.Timeout.IsNull() && !model.Timeout.IsUnknown() {
+			timeout, err := time.ParseDuration(model.Timeout.ValueString())
+			if err != nil {
+				diags.AddAttributeError(attribute.AtName("timeout"), "Invalid Timeout", fmt.Sprintf("Unable to parse timeout, got error: %s", err))
+			} else if timeout <= 0 {
+				diags.AddAttributeError(attribute.AtName("timeout"), "Invalid Timeout", "The timeout must be positive.")
+			}
+			source.Timeout = timeout
+		}
+
+		if source.Retries < 0 {
+			diags.AddAttributeError(attribute.AtName("retries"), "Invalid Retries", "The number of retries can't be negative.")
+		}
+
+		if source.SHA256 != "" {
+			if raw, err := hex.DecodeString(source.SHA256); err != nil || len(raw) != sha256.Size {
+				diags.AddAttributeError(attribute.AtName("sha256"), "Invalid Checksum", "The sha256 checksum must be 64 hexadecimal characters.")
+			}
+		}
+
+		if !model.Headers.IsNull() && !model.Headers.IsUnknown() {
+			diags.Append(model.Headers.ElementsAs(ctx, &source.Headers, false)...)
+		}
+
+		sources = append(sources, source)
+	}
+
+	return
+}
+
+// configSourcesUnknown reports whether any config_source value is unknown, in
+// which case remote sources can't be fetched yet.
+func configSourcesUnknown(models []ConfigSourceModel) bool {
+	for _, model := range models {
+		if model.Url.IsUnknown() || model.BearerToken.IsUnknown() || model.Username.IsUnknown() || model.Password.IsUnknown() ||
+			model.Headers.IsUnknown() || model.CaCertificate.IsUnknown() || model.Timeout.IsUnknown() || model.Retries.IsUnknown() || model.Sha256.IsUnknown() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findConfigSource returns the settings for the given URL, or nil when the URL
+// is fetched without any.
+func findConfigSource(url string, sources []ConfigSource) *ConfigSource {
+	for i := range sources {
+		if sources[i].URL == url {
+			return &sources[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *ConfigSource) httpClient() (*http.Client, error) {
+	client := &http.Client{Timeout: s.Timeout}
+
+	if s.CACertificate != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(s.CACertificate)) {
+			return nil, fmt.Errorf("no certificates found in ca_certificate")
+		}
+
+		// The default transport is replaced when it is wrapped, e.g. by a tracer
+		transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+		if default_transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			transport = default_transport.Clone()
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		client.Transport = transport
+	}
+
+	return client, nil
+}
+
+func (s *ConfigSource) authorize(request *http.Request) {
+	for key, value := range s.Headers {
+		request.Header.Set(key, value)
+	}
+
+	switch {
+	case s.BearerToken != "":
+		request.Header.Set("Authorization", "Bearer "+s.BearerToken)
+	case s.Username != "":
+		request.SetBasicAuth(s.Username, s.Password)
+	}
+}
+
+// verify checks the fetched document against the pinned checksum.
+func (s *ConfigSource) verify(data []byte) error {
+	if s.SHA256 == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	if checksum := hex.EncodeToString(sum[:]); checksum != s.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s, expected sha256 %s but the fetched document has %s", s.URL, s.SHA256, checksum)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestToConfigSourcesTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		valid   bool
+	}{
+		{timeout: "10s", valid: true},
+		{timeout: "0s", valid: false},
+		{timeout: "-5s", valid: false},
+		{timeout: "soon", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.timeout, func(t *testing.T) {
+			models := []ConfigSourceModel{{
+				Url:     types.StringValue("https://example.com/config.json"),
+				Timeout: types.StringValue(test.timeout),
+				Headers: types.MapNull(types.StringType),
+			}}
+
+			_, diags := toConfigSources(context.Background(), models)
+			if diags.HasError() == test.valid {
+				t.Errorf("expected valid %t, got %v", test.valid, diags)
+			}
+		})
+	}
+}
+
+func TestGetHttpContentCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	source := &ConfigSource{URL: server.URL, Timeout: time.Second, Retries: 5}
+	start := time.Now()
+	_, _, err := getHttpContent(ctx, server.URL, source)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the retries to stop with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the retries to stop early, took %s", elapsed)
+	}
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.StringTypable = ConfigStringType{}
+var _ basetypes.StringValuableWithSemanticEquals = ConfigString{}
+
+// ConfigStringType is the type of config inputs. Inline JSON and YAML are
+// compared by value, so reformatting or reordering keys doesn't show up as a
+// change. Other sources are compared as they are written.
+type ConfigStringType struct {
+	basetypes.StringType
+}
+
+func (t ConfigStringType) String() string {
+	return "ConfigStringType"
+}
+
+func (t ConfigStringType) ValueType(ctx context.Context) attr.Value {
+	return ConfigString{}
+}
+
+func (t ConfigStringType) Equal(o attr.Type) bool {
+	other, ok := o.(ConfigStringType)
+	if !ok {
+		return false
+	}
+
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t ConfigStringType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return ConfigString{StringValue: in}, nil
+}
+
+func (t ConfigStringType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	value, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	string_value, ok := value.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", value)
+	}
+
+	config_value, diags := t.ValueFromString(ctx, string_value)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to ConfigString: %v", diags)
+	}
+
+	return config_value, nil
+}
+
+// ConfigString is a config input, see ConfigStringType.
+type ConfigString struct {
+	basetypes.StringValue
+}
+
+func NewConfigStringNull() ConfigString {
+	return ConfigString{StringValue: basetypes.NewStringNull()}
+}
+
+func NewConfigStringValue(value string) ConfigString {
+	return ConfigString{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v ConfigString) Type(ctx context.Context) attr.Type {
+	return ConfigStringType{}
+}
+
+func (v ConfigString) Equal(o attr.Value) bool {
+	other, ok := o.(ConfigString)
+	if !ok {
+		return false
+	}
+
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v ConfigString) StringSemanticEquals(ctx context.Context, new_valuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	new_value, ok := new_valuable.(ConfigString)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this issue to the provider developers.", v, new_valuable),
+		)
+		return false, diags
+	}
+
+	return configSemanticEquals(v.ValueString(), new_value.ValueString()), diags
+}
+
+// configSemanticEquals reports whether two inline configs hold the same
+// values. Sources are only equal when they are written the same.
+func configSemanticEquals(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	a_value, err := decodeInlineConfig(a)
+	if err != nil {
+		return false
+	}
+	b_value, err := decodeInlineConfig(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a_value, b_value)
+}
+
+// decodeInlineConfig decodes inline JSON or YAML, other sources can't be
+// decoded without being resolved.
+func decodeInlineConfig(config_input string) (interface{}, error) {
+	config, err := parseInlineConfig(config_input)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(config)
+}
+
+// parseInlineConfig converts inline JSON or YAML into JSON, without needing a
+// configured provider.
+func parseInlineConfig(config_input string) (string, error) {
+	switch configProtocol(config_input) {
+	case "json":
+		return config_input, nil
+	case "yaml":
+		return yamlToJSON(strings.TrimPrefix(config_input, "yaml://"))
+	}
+
+	return "", fmt.Errorf("config is not inline")
+}
+
+// normalizeJSON re-encodes JSON compactly with sorted keys, numbers are kept
+// as they are written.
+func normalizeJSON(config string) (string, error) {
+	value, err := decodeJSON(config)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
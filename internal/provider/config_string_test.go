@@ -0,0 +1,38 @@
+package provider
+
+import "testing"
+
+func TestConfigSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{"identical", `{"a":1}`, `{"a":1}`, true},
+		{"formatting", `{"a":1,"b":[1,2]}`, "{\n  \"a\": 1,\n  \"b\": [ 1, 2 ]\n}", true},
+		{"key order", `{"a":1,"b":2}`, `{"b":2,"a":1}`, true},
+		{"nested key order", `{"a":{"x":1,"y":2}}`, `{"a":{"y":2,"x":1}}`, true},
+		{"different value", `{"a":1}`, `{"a":2}`, false},
+		{"list order", `{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{"number type", `{"a":1}`, `{"a":"1"}`, false},
+		{"yaml and json", "yaml://a: 1\nb: [x]\n", `{"b":["x"],"a":1}`, true},
+		{"yaml formatting", "yaml://a: {b: 1}", "yaml://a:\n  b: 1\n", true},
+		{"same file", "file://config.json", "file://config.json", true},
+		{"different file", "file://config.json", "file://./config.json", false},
+		{"file and inline", "file://config.json", `{}`, false},
+		{"environment variable", "env://CONFIG", "env://CONFIG", true},
+		{"invalid json", `{"a":`, `{"a": `, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := configSemanticEquals(test.a, test.b); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+			if actual := configSemanticEquals(test.b, test.a); actual != test.expected {
+				t.Errorf("expected %t with swapped arguments, got %t", test.expected, actual)
+			}
+		})
+	}
+}
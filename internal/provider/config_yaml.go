@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+func isYAMLPath(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	return extension == ".yaml" || extension == ".yml"
+}
+
+func isYAMLURL(raw_url string) bool {
+	parsed, err := url.Parse(raw_url)
+	if err != nil {
+		return false
+	}
+
+	return isYAMLPath(parsed.Path)
+}
+
+func isYAMLContentType(content_type string) bool {
+	media_type, _, err := mime.ParseMediaType(content_type)
+	if err != nil {
+		return false
+	}
+
+	switch media_type {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+
+	return false
+}
+
+// yamlToJSON converts a YAML config into JSON. Only a single document holding
+// an object is accepted, as the result is injected as a config object.
+func yamlToJSON(config string) (string, error) {
+	documents := 0
+	decoder := yamlv3.NewDecoder(strings.NewReader(config))
+	for {
+		var node yamlv3.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to parse yaml: %w", err)
+		}
+		documents++
+
+		if documents > 1 {
+			continue
+		}
+		if len(node.Content) == 0 {
+			return "", errors.New("yaml config is empty, expected an object")
+		}
+		if node.Content[0].Kind != yamlv3.MappingNode {
+			return "", fmt.Errorf("yaml config must be an object, found %s on line %d", yamlKind(node.Content[0]), node.Content[0].Line)
+		}
+	}
+
+	switch {
+	case documents == 0:
+		return "", errors.New("yaml config is empty, expected an object")
+	case documents > 1:
+		return "", fmt.Errorf("yaml config contains %d documents, only a single document is supported", documents)
+	}
+
+	raw, err := yaml.YAMLToJSON([]byte(config))
+	if err != nil {
+		return "", fmt.Errorf("unable to convert yaml to json: %w", err)
+	}
+
+	return string(raw), nil
+}
+
+func yamlKind(node *yamlv3.Node) string {
+	switch node.Kind {
+	case yamlv3.SequenceNode:
+		return "a list"
+	case yamlv3.ScalarNode:
+		if node.Tag == "!!null" {
+			return "null"
+		}
+		return "a scalar value"
+	case yamlv3.AliasNode:
+		return "an alias"
+	}
+
+	return "an unsupported value"
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYamlToJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+		err      string
+	}{
+		{
+			name:     "object",
+			config:   "replicas: 2\nimage:\n  tag: \"1.27\"\n",
+			expected: `{"image":{"tag":"1.27"},"replicas":2}`,
+		},
+		{
+			name:     "single document with separator",
+			config:   "---\nenabled: true\n",
+			expected: `{"enabled":true}`,
+		},
+		{
+			name:     "anchors",
+			config:   "base: &base {a: 1}\nother: *base\n",
+			expected: `{"base":{"a":1},"other":{"a":1}}`,
+		},
+		{
+			name:   "empty",
+			config: "",
+			err:    "yaml config is empty",
+		},
+		{
+			name:   "comment only",
+			config: "# nothing\n",
+			err:    "yaml config is empty",
+		},
+		{
+			name:   "list",
+			config: "- a\n- b\n",
+			err:    "must be an object, found a list on line 1",
+		},
+		{
+			name:   "scalar",
+			config: "\n\nvalue\n",
+			err:    "must be an object, found a scalar value on line 3",
+		},
+		{
+			name:   "null",
+			config: "null\n",
+			err:    "must be an object, found null",
+		},
+		{
+			name:   "multiple documents",
+			config: "a: 1\n---\nb: 2\n",
+			err:    "contains 2 documents",
+		},
+		{
+			name:   "invalid",
+			config: "a: [\n",
+			err:    "unable to parse yaml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := yamlToJSON(test.config)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaDiffDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaDiffDataSource{}
+
+// diffStrategies are the strategies tanka is able to diff with.
+var diffStrategies = []string{"native", "validate", "server", "subset"}
+
+// diffChangeAttrTypes describes an entry of the changes list.
+var diffChangeAttrTypes = map[string]attr.Type{
+	"api_version": types.StringType,
+	"kind":        types.StringType,
+	"namespace":   types.StringType,
+	"name":        types.StringType,
+	"action":      types.StringType,
+}
+
+func NewTankaDiffDataSource() datasource.DataSource {
+	return &TankaDiffDataSource{}
+}
+
+// TankaDiffDataSource defines the data source implementation.
+type TankaDiffDataSource struct {
+	client *Client
+}
+
+// TankaDiffDataSourceModel describes the data source data model.
+type TankaDiffDataSourceModel struct {
+	ConfigInputsModel
+
+	Namespace     types.String        `tfsdk:"namespace"`
+	SourcePath    types.String        `tfsdk:"source_path"`
+	BaseDir       types.String        `tfsdk:"base_dir"`
+	ConfigSources []ConfigSourceModel `tfsdk:"config_source"`
+	Name          types.String        `tfsdk:"name"`
+	Targets       types.List          `tfsdk:"targets"`
+	Strategy      types.String        `tfsdk:"strategy"`
+	WithPrune     types.Bool          `tfsdk:"with_prune"`
+	FailOnChanges types.Bool          `tfsdk:"fail_on_changes"`
+	Diff          types.String        `tfsdk:"diff"`
+	HasChanges    types.Bool          `tfsdk:"has_changes"`
+	Changes       types.List          `tfsdk:"changes"`
+}
+
+func (d *TankaDiffDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_diff"
+}
+
+func (d *TankaDiffDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := environmentDataSourceAttributes()
+
+	attributes["name"] = schema.StringAttribute{
+		MarkdownDescription: "Name of the inline environment to diff, required when the main file returns more than one.",
+		Optional:            true,
+	}
+	attributes["targets"] = schema.ListAttribute{
+		MarkdownDescription: "Only diff the manifests matching one of the `kind/name` regular expressions, matched case-insensitively. Prefix an expression with `!` to exclude the matching manifests instead.",
+		ElementType:         types.StringType,
+		Optional:            true,
+	}
+	attributes["strategy"] = schema.StringAttribute{
+		MarkdownDescription: "The diff strategy, one of `native`, `validate`, `server` or `subset`. Defaults to the `diffStrategy` of the environment.",
+		Optional:            true,
+	}
+	attributes["with_prune"] = schema.BoolAttribute{
+		MarkdownDescription: "Include the objects of the environment that are no longer rendered and would be pruned.",
+		Optional:            true,
+	}
+	attributes["fail_on_changes"] = schema.BoolAttribute{
+		MarkdownDescription: "Fail with an error listing the changed objects when the cluster differs from the environment.",
+		Optional:            true,
+	}
+	attributes["diff"] = schema.StringAttribute{
+		MarkdownDescription: "The differences in unified `diff(1)` format, empty when the cluster matches the environment.",
+		Computed:            true,
+	}
+	attributes["has_changes"] = schema.BoolAttribute{
+		MarkdownDescription: "Whether the cluster differs from the environment.",
+		Computed:            true,
+	}
+	attributes["changes"] = schema.ListNestedAttribute{
+		MarkdownDescription: "The changed objects in the order of the diff.",
+		Computed:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"api_version": schema.StringAttribute{
+					MarkdownDescription: "The API version of the object.",
+					Computed:            true,
+				},
+				"kind": schema.StringAttribute{
+					MarkdownDescription: "The kind of the object.",
+					Computed:            true,
+				},
+				"namespace": schema.StringAttribute{
+					MarkdownDescription: "The namespace of the object, empty for cluster-wide objects.",
+					Computed:            true,
+				},
+				"name": schema.StringAttribute{
+					MarkdownDescription: "The name of the object.",
+					Computed:            true,
+				},
+				"action": schema.StringAttribute{
+					MarkdownDescription: "What applying the environment does to the object, one of `create`, `modify` or `delete`.",
+					Computed:            true,
+				},
+			},
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Compares a Tanka environment with the live state of the cluster, with the same inputs as `tanka_release`.",
+
+		Attributes: attributes,
+
+		Blocks: map[string]schema.Block{
+			"config_source": configSourceDataSourceBlock(),
+		},
+	}
+}
+
+func (d *TankaDiffDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaDiffDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateConfigInputs(ctx, req.Config)...)
+
+	var strategy types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("strategy"), &strategy)...)
+	if strategy.IsNull() || strategy.IsUnknown() {
+		return
+	}
+
+	for _, known := range diffStrategies {
+		if strategy.ValueString() == known {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(path.Root("strategy"), "Invalid Strategy", fmt.Sprintf("Unknown diff strategy %q, expected one of %s.", strategy.ValueString(), strings.Join(diffStrategies, ", ")))
+}
+
+func (d *TankaDiffDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaDiffDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Namespace = stringOrDefault(data.Namespace, defaultNamespace)
+	data.SourcePath = stringOrDefault(data.SourcePath, defaultSourcePath)
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, data.ConfigSources)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resolved, diags := d.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var targets []string
+	resp.Diagnostics.Append(data.Targets.ElementsAs(ctx, &targets, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diff, err := d.client.Diff(d.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, "", resolvePath(opts.BaseDir, data.SourcePath.ValueString()), data.Name.ValueString(), targets, data.Strategy.ValueString(), data.WithPrune.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Diff Error", fmt.Sprintf("Unable to diff tanka package, got error: %s", err))
+		return
+	}
+
+	data.Diff = types.StringValue("")
+	if diff != nil {
+		data.Diff = types.StringValue(*diff)
+	}
+
+	changes := parseDiffChanges(data.Diff.ValueString())
+	data.HasChanges = types.BoolValue(len(changes) > 0)
+
+	elements := make([]attr.Value, 0, len(changes))
+	for _, change := range changes {
+		element, diags := types.ObjectValue(diffChangeAttrTypes, map[string]attr.Value{
+			"api_version": types.StringValue(change.APIVersion),
+			"kind":        types.StringValue(change.Kind),
+			"namespace":   types.StringValue(change.Namespace),
+			"name":        types.StringValue(change.Name),
+			"action":      types.StringValue(change.Action),
+		})
+		resp.Diagnostics.Append(diags...)
+		elements = append(elements, element)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Changes, diags = types.ListValue(types.ObjectType{AttrTypes: diffChangeAttrTypes}, elements)
+	resp.Diagnostics.Append(diags...)
+
+	if data.FailOnChanges.ValueBool() && len(changes) > 0 {
+		summary := make([]string, 0, len(changes))
+		for _, change := range changes {
+			summary = append(summary, fmt.Sprintf("  %s %s", change.Action, change))
+		}
+		resp.Diagnostics.AddError("Changes Detected", fmt.Sprintf("The cluster differs from the environment in %d objects:\n%s", len(changes), strings.Join(summary, "\n")))
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// diffChange is an object found in a diff.
+type diffChange struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Action     string
+}
+
+func (c diffChange) String() string {
+	if c.Namespace == "" {
+		return fmt.Sprintf("%s/%s", c.Kind, c.Name)
+	}
+
+	return fmt.Sprintf("%s/%s in %s", c.Kind, c.Name, c.Namespace)
+}
+
+// parseDiffChanges finds the objects in a diff. Each object is compared as a
+// file, named `<group>.<version>.<kind>.<namespace>.<name>` by kubectl and
+// `<group>-<version>.<kind>.<namespace>.<name>` by the static differs of tanka.
+// Objects that don't exist on one side are compared with an empty file.
+func parseDiffChanges(diff string) (changes []diffChange) {
+	current := -1
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff ") {
+			fields := strings.Fields(line)
+			change, ok := parseDiffName(filepath.Base(fields[len(fields)-1]))
+			if !ok {
+				current = -1
+				continue
+			}
+
+			change.Action = "modify"
+			changes = append(changes, change)
+			current = len(changes) - 1
+			continue
+		}
+
+		// The first hunk tells whether one of the sides is empty
+		if current < 0 || !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@ -0,0 "):
+			changes[current].Action = "create"
+		case strings.Contains(line, " +0,0 @@"):
+			changes[current].Action = "delete"
+		}
+		current = -1
+	}
+
+	return
+}
+
+// parseDiffName splits the file name of an object into its parts. Kinds start
+// with an upper case letter, group and version never do. Namespaces can't
+// contain dots, names can.
+func parseDiffName(filename string) (change diffChange, ok bool) {
+	filename = strings.TrimPrefix(filename, "MERGED-")
+
+	parts := strings.Split(filename, ".")
+	for i, part := range parts {
+		if part == "" || part[0] < 'A' || part[0] > 'Z' {
+			continue
+		}
+		if i == 0 || len(parts) < i+3 {
+			return change, false
+		}
+
+		api_version := strings.Join(parts[:i], ".")
+		if separator := strings.LastIndexAny(api_version, ".-"); separator >= 0 {
+			api_version = api_version[:separator] + "/" + api_version[separator+1:]
+		}
+
+		return diffChange{
+			APIVersion: api_version,
+			Kind:       part,
+			Namespace:  parts[i+1],
+			Name:       strings.Join(parts[i+2:], "."),
+		}, true
+	}
+
+	return change, false
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiffName(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected diffChange
+		ok       bool
+	}{
+		{"core", "v1.ConfigMap.default.settings", diffChange{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings"}, true},
+		{"grouped", "apps.v1.Deployment.default.web", diffChange{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}, true},
+		{"dotted group", "networking.k8s.io.v1.Ingress.default.web", diffChange{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Namespace: "default", Name: "web"}, true},
+		{"static differ", "apps-v1.Deployment.default.web", diffChange{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}, true},
+		{"dashed group", "cert-manager.io.v1.Certificate.default.web", diffChange{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Namespace: "default", Name: "web"}, true},
+		{"dashed group of the static differ", "cert-manager.io-v1.Certificate.default.web", diffChange{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Namespace: "default", Name: "web"}, true},
+		{"cluster-scoped", "v1.Namespace..monitoring", diffChange{APIVersion: "v1", Kind: "Namespace", Name: "monitoring"}, true},
+		{"cluster-scoped grouped", "rbac.authorization.k8s.io.v1.ClusterRole..view", diffChange{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "view"}, true},
+		{"dotted name", "v1.Secret.default.tls.example.com", diffChange{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "tls.example.com"}, true},
+		{"dotted cluster-scoped name", "apiextensions.k8s.io.v1.CustomResourceDefinition..certificates.cert-manager.io", diffChange{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "certificates.cert-manager.io"}, true},
+		{"merged prefix", "MERGED-apps.v1.Deployment.default.web", diffChange{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}, true},
+		{"no kind", "apps.v1.deployment.default.web", diffChange{}, false},
+		{"no version", "Deployment.default.web", diffChange{}, false},
+		{"no name", "apps.v1.Deployment.default", diffChange{}, false},
+		{"empty", "", diffChange{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok := parseDiffName(test.filename)
+			if ok != test.ok {
+				t.Fatalf("expected ok to be %t, got %t", test.ok, ok)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseDiffChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected []diffChange
+	}{
+		{
+			name: "modify",
+			diff: `diff -u -N /tmp/LIVE-1/apps.v1.Deployment.default.web /tmp/MERGED-2/apps.v1.Deployment.default.web
+--- /tmp/LIVE-1/apps.v1.Deployment.default.web
++++ /tmp/MERGED-2/apps.v1.Deployment.default.web
+@@ -6,7 +6,7 @@
+ spec:
+-  replicas: 1
++  replicas: 2
+`,
+			expected: []diffChange{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web", Action: "modify"}},
+		},
+		{
+			name: "create",
+			diff: `diff -u -N /tmp/LIVE-1/v1.ConfigMap.default.settings /tmp/MERGED-2/v1.ConfigMap.default.settings
+--- /tmp/LIVE-1/v1.ConfigMap.default.settings
++++ /tmp/MERGED-2/v1.ConfigMap.default.settings
+@@ -0,0 +1,6 @@
++apiVersion: v1
++kind: ConfigMap
+`,
+			expected: []diffChange{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings", Action: "create"}},
+		},
+		{
+			name: "create single line",
+			diff: `diff -u -N /tmp/LIVE-1/v1.Namespace..monitoring /tmp/MERGED-2/v1.Namespace..monitoring
+@@ -0,0 +1 @@
++{}
+`,
+			expected: []diffChange{{APIVersion: "v1", Kind: "Namespace", Name: "monitoring", Action: "create"}},
+		},
+		{
+			name: "delete",
+			diff: `diff -u -N /tmp/LIVE-1/rbac.authorization.k8s.io.v1.ClusterRole..view /tmp/MERGED-2/rbac.authorization.k8s.io.v1.ClusterRole..view
+--- /tmp/LIVE-1/rbac.authorization.k8s.io.v1.ClusterRole..view
++++ /tmp/MERGED-2/rbac.authorization.k8s.io.v1.ClusterRole..view
+@@ -1,4 +0,0 @@
+-apiVersion: rbac.authorization.k8s.io/v1
+-kind: ClusterRole
+`,
+			expected: []diffChange{{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "view", Action: "delete"}},
+		},
+		{
+			name: "only the first hunk counts",
+			diff: `diff -u -N /tmp/LIVE-1/v1.Secret.default.tls.example.com /tmp/MERGED-2/v1.Secret.default.tls.example.com
+@@ -1,3 +1,3 @@
+-a: 1
++a: 2
+@@ -0,0 +1,1 @@
++b: 1
+`,
+			expected: []diffChange{{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "tls.example.com", Action: "modify"}},
+		},
+		{
+			name: "several objects",
+			diff: `diff -u -N /tmp/LIVE-1/v1.ConfigMap.default.a /tmp/MERGED-2/v1.ConfigMap.default.a
+@@ -0,0 +1,2 @@
++a
++b
+diff -u -N /tmp/LIVE-1/unknown /tmp/MERGED-2/unknown
+@@ -0,0 +1,1 @@
++a
+diff -u -N /tmp/LIVE-1/v1.ConfigMap.default.b /tmp/MERGED-2/MERGED-v1.ConfigMap.default.b
+@@ -1,2 +0,0 @@
+-a
+-b
+diff -u -N /tmp/LIVE-1/v1.ConfigMap.default.c /tmp/MERGED-2/v1.ConfigMap.default.c
+@@ -1 +1 @@
+-a
++b
+`,
+			expected: []diffChange{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "a", Action: "create"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "b", Action: "delete"},
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "c", Action: "modify"},
+			},
+		},
+		{
+			name: "empty",
+			diff: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := parseDiffChanges(test.diff)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/grafana/tanka/pkg/tanka"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaEnvironmentsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaEnvironmentsDataSource{}
+
+const defaultEnvironmentsPath = "tanka/environments"
+
+// environmentAttrTypes describes an entry of the environments list.
+var environmentAttrTypes = map[string]attr.Type{
+	"name":       types.StringType,
+	"path":       types.StringType,
+	"namespace":  types.StringType,
+	"api_server": types.StringType,
+	"labels":     types.MapType{ElemType: types.StringType},
+	"spec":       types.StringType,
+}
+
+func NewTankaEnvironmentsDataSource() datasource.DataSource {
+	return &TankaEnvironmentsDataSource{}
+}
+
+// TankaEnvironmentsDataSource defines the data source implementation.
+type TankaEnvironmentsDataSource struct {
+	client *Client
+}
+
+// TankaEnvironmentsDataSourceModel describes the data source data model.
+type TankaEnvironmentsDataSourceModel struct {
+	ConfigInputsModel
+
+	Path          types.String        `tfsdk:"path"`
+	Selector      types.String        `tfsdk:"selector"`
+	Namespace     types.String        `tfsdk:"namespace"`
+	BaseDir       types.String        `tfsdk:"base_dir"`
+	ConfigSources []ConfigSourceModel `tfsdk:"config_source"`
+	Environments  types.List          `tfsdk:"environments"`
+}
+
+func (d *TankaEnvironmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_environments"
+}
+
+func (d *TankaEnvironmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := environmentDataSourceAttributes()
+	delete(attributes, "source_path")
+
+	attributes["path"] = schema.StringAttribute{
+		MarkdownDescription: "Directory that is searched recursively for environments. Relative paths are resolved against the base directory. Defaults to `tanka/environments`.",
+		Optional:            true,
+		Computed:            true,
+	}
+	attributes["selector"] = schema.StringAttribute{
+		MarkdownDescription: "Kubernetes label selector the labels of the environments have to match, e.g. `team=infra,tier!=dev`.",
+		Optional:            true,
+	}
+	attributes["namespace"] = schema.StringAttribute{
+		MarkdownDescription: "The Kubernetes namespace passed to inline environments. Defaults to `default`.",
+		Optional:            true,
+		Computed:            true,
+	}
+	attributes["environments"] = schema.ListNestedAttribute{
+		MarkdownDescription: "The environments found, ordered by path and name.",
+		Computed:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					MarkdownDescription: "The name of the environment.",
+					Computed:            true,
+				},
+				"path": schema.StringAttribute{
+					MarkdownDescription: "Absolute path of the environment directory, usable as `source_path` of `tanka_release`.",
+					Computed:            true,
+				},
+				"namespace": schema.StringAttribute{
+					MarkdownDescription: "The default namespace of the environment.",
+					Computed:            true,
+				},
+				"api_server": schema.StringAttribute{
+					MarkdownDescription: "The API server the environment is applied to.",
+					Computed:            true,
+				},
+				"labels": schema.MapAttribute{
+					MarkdownDescription: "The labels of the environment.",
+					ElementType:         types.StringType,
+					Computed:            true,
+				},
+				"spec": schema.StringAttribute{
+					MarkdownDescription: "The full `spec` of the environment encoded as JSON.",
+					Computed:            true,
+				},
+			},
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Finds the Tanka environments in a directory, optionally filtered by a label selector.",
+
+		Attributes: attributes,
+
+		Blocks: map[string]schema.Block{
+			"config_source": configSourceDataSourceBlock(),
+		},
+	}
+}
+
+func (d *TankaEnvironmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaEnvironmentsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateConfigInputs(ctx, req.Config)...)
+
+	var selector types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("selector"), &selector)...)
+	if selector.IsNull() || selector.IsUnknown() {
+		return
+	}
+
+	if _, err := labels.Parse(selector.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("selector"), "Invalid Selector", fmt.Sprintf("Unable to parse label selector, got error: %s", err))
+	}
+}
+
+func (d *TankaEnvironmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaEnvironmentsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Path = stringOrDefault(data.Path, defaultEnvironmentsPath)
+	data.Namespace = stringOrDefault(data.Namespace, defaultNamespace)
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, data.ConfigSources)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resolved, diags := d.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	selector, err := labels.Parse(data.Selector.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("selector"), "Invalid Selector", fmt.Sprintf("Unable to parse label selector, got error: %s", err))
+		return
+	}
+
+	search_path := resolvePath(opts.BaseDir, data.Path.ValueString())
+	root, err := jpath.FindRoot(search_path)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Find Error", fmt.Sprintf("Unable to find the tanka project of %s, got error: %s", search_path, err))
+		return
+	}
+
+	// Inline environments are evaluated with the same arguments as a release
+	base_opts := createBaseOpts(d.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, "")
+	envs, err := tanka.FindEnvs(search_path, tanka.FindOpts{JsonnetOpts: base_opts.JsonnetOpts, Selector: selector})
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Find Error", fmt.Sprintf("Unable to find tanka environments in %s, got error: %s", search_path, err))
+		return
+	}
+
+	sort.SliceStable(envs, func(i, j int) bool {
+		if envs[i].Metadata.Namespace != envs[j].Metadata.Namespace {
+			return envs[i].Metadata.Namespace < envs[j].Metadata.Namespace
+		}
+		return envs[i].Metadata.Name < envs[j].Metadata.Name
+	})
+
+	elements := make([]attr.Value, 0, len(envs))
+	for _, env := range envs {
+		spec, err := json.Marshal(env.Spec)
+		if err != nil {
+			resp.Diagnostics.AddError("Marshal Error", fmt.Sprintf("Unable to encode the spec of environment %s, got error: %s", env.Metadata.Name, err))
+			return
+		}
+
+		env_labels, diags := types.MapValueFrom(ctx, types.StringType, env.Metadata.Labels)
+		resp.Diagnostics.Append(diags...)
+
+		// The namespace of the metadata holds the main file relative to the
+		// project root
+		element, diags := types.ObjectValue(environmentAttrTypes, map[string]attr.Value{
+			"name":       types.StringValue(env.Metadata.Name),
+			"path":       types.StringValue(filepath.Dir(filepath.Join(root, env.Metadata.Namespace))),
+			"namespace":  types.StringValue(env.Spec.Namespace),
+			"api_server": types.StringValue(env.Spec.APIServer),
+			"labels":     env_labels,
+			"spec":       types.StringValue(string(spec)),
+		})
+		resp.Diagnostics.Append(diags...)
+		elements = append(elements, element)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Environments, diags = types.ListValue(types.ObjectType{AttrTypes: environmentAttrTypes}, elements)
+	resp.Diagnostics.Append(diags...)
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaEvalDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaEvalDataSource{}
+
+func NewTankaEvalDataSource() datasource.DataSource {
+	return &TankaEvalDataSource{}
+}
+
+// TankaEvalDataSource defines the data source implementation.
+type TankaEvalDataSource struct {
+	client *Client
+}
+
+// TankaEvalDataSourceModel describes the data source data model.
+type TankaEvalDataSourceModel struct {
+	Path        types.String  `tfsdk:"path"`
+	Snippet     types.String  `tfsdk:"snippet"`
+	BaseDir     types.String  `tfsdk:"base_dir"`
+	Expression  types.String  `tfsdk:"expression"`
+	TLAStr      types.Map     `tfsdk:"tla_str"`
+	TLACode     types.Map     `tfsdk:"tla_code"`
+	ExtStr      types.Map     `tfsdk:"ext_str"`
+	ExtCode     types.Map     `tfsdk:"ext_code"`
+	ImportPaths types.List    `tfsdk:"import_paths"`
+	Result      types.Dynamic `tfsdk:"result"`
+	Json        types.String  `tfsdk:"json"`
+}
+
+func (d *TankaEvalDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_eval"
+}
+
+func (d *TankaEvalDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Evaluates a Jsonnet file or snippet with the native functions of Tanka and returns the result.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The Jsonnet file to evaluate. A directory evaluates its `main.jsonnet`, like `tk eval` does for an environment. Relative paths are resolved against the base directory. Conflicts with `snippet`.",
+				Optional:            true,
+			},
+			"snippet": schema.StringAttribute{
+				MarkdownDescription: "Inline Jsonnet to evaluate. Relative imports are resolved against the base directory. Conflicts with `path`.",
+				Optional:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative paths are resolved against. Overrides the `base_dir` of the provider.",
+				Optional:            true,
+			},
+			"expression": schema.StringAttribute{
+				MarkdownDescription: "Only evaluate the part of the result selected by the expression, e.g. `deployment.spec` or `['my-service'].port`, like `tk eval -e`.",
+				Optional:            true,
+			},
+			"tla_str": schema.MapAttribute{
+				MarkdownDescription: "Top-level arguments passed as strings.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"tla_code": schema.MapAttribute{
+				MarkdownDescription: "Top-level arguments passed as Jsonnet code.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"ext_str": schema.MapAttribute{
+				MarkdownDescription: "External variables passed as strings, available through `std.extVar()`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"ext_code": schema.MapAttribute{
+				MarkdownDescription: "External variables passed as Jsonnet code, available through `std.extVar()`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"import_paths": schema.ListAttribute{
+				MarkdownDescription: "Additional library search directories, searched before the `lib` and `vendor` directories of the Tanka project. Relative paths are resolved against the base directory.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"result": schema.DynamicAttribute{
+				MarkdownDescription: "The result of the evaluation, typed the same way `jsondecode()` would type it.",
+				Computed:            true,
+			},
+			"json": schema.StringAttribute{
+				MarkdownDescription: "The result of the evaluation encoded as JSON.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *TankaEvalDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaEvalDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data TankaEvalDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Path.IsNull() && !data.Snippet.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("snippet"), "Conflicting Configuration", "`snippet` can't be set together with `path`.")
+	}
+	if data.Path.IsNull() && data.Snippet.IsNull() {
+		resp.Diagnostics.AddError("Missing Configuration", "Either `path` or `snippet` has to be set.")
+	}
+
+	// The same argument can't be passed twice
+	duplicates := map[string]string{"tla_code": "tla_str", "ext_code": "ext_str"}
+	values := map[string]types.Map{"tla_str": data.TLAStr, "tla_code": data.TLACode, "ext_str": data.ExtStr, "ext_code": data.ExtCode}
+	for code_attribute, str_attribute := range duplicates {
+		if values[code_attribute].IsUnknown() || values[str_attribute].IsUnknown() {
+			continue
+		}
+		for name := range values[code_attribute].Elements() {
+			if _, ok := values[str_attribute].Elements()[name]; ok {
+				resp.Diagnostics.AddAttributeError(path.Root(code_attribute).AtMapKey(name), "Conflicting Configuration", fmt.Sprintf("`%s` is set in both `%s` and `%s`.", name, code_attribute, str_attribute))
+			}
+		}
+	}
+}
+
+func (d *TankaEvalDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaEvalDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	base_dir := d.client.BaseDir
+	if !data.BaseDir.IsNull() {
+		base_dir = data.BaseDir.ValueString()
+	}
+
+	var tla_str, tla_code, ext_str, ext_code map[string]string
+	var import_paths []string
+	resp.Diagnostics.Append(data.TLAStr.ElementsAs(ctx, &tla_str, false)...)
+	resp.Diagnostics.Append(data.TLACode.ElementsAs(ctx, &tla_code, false)...)
+	resp.Diagnostics.Append(data.ExtStr.ElementsAs(ctx, &ext_str, false)...)
+	resp.Diagnostics.Append(data.ExtCode.ElementsAs(ctx, &ext_code, false)...)
+	resp.Diagnostics.Append(data.ImportPaths.ElementsAs(ctx, &import_paths, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts := jsonnet.Opts{TLACode: tla_code, ExtCode: ext_code}
+	if err := injectStrings(&opts.TLACode, tla_str); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("tla_str"), "Marshal Error", fmt.Sprintf("Unable to encode top-level arguments, got error: %s", err))
+		return
+	}
+	if err := injectStrings(&opts.ExtCode, ext_str); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ext_str"), "Marshal Error", fmt.Sprintf("Unable to encode external variables, got error: %s", err))
+		return
+	}
+	for _, import_path := range import_paths {
+		opts.ImportPaths = append(opts.ImportPaths, resolvePath(base_dir, import_path))
+	}
+
+	attribute := path.Root("snippet")
+	filename := filepath.Join(resolvePath(base_dir, "."), snippetFilename)
+	if !data.Path.IsNull() {
+		attribute = path.Root("path")
+		filename = resolvePath(base_dir, data.Path.ValueString())
+
+		if info, err := os.Stat(filename); err == nil && info.IsDir() {
+			filename = filepath.Join(filename, jpath.DefaultEntrypoint)
+		}
+	}
+
+	raw, err := evaluateJsonnet(filename, data.Snippet.ValueString(), data.Expression.ValueString(), opts)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(attribute, "Jsonnet Evaluation Error", fmt.Sprintf("Unable to evaluate jsonnet, got error: %s", err))
+		return
+	}
+
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse the result, got error: %s", err))
+		return
+	}
+
+	result, err := interfaceToAttr(decoded)
+	if err != nil {
+		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to convert the result, got error: %s", err))
+		return
+	}
+
+	// The output of the VM is indented, the state keeps it compact
+	compact, err := json.Marshal(decoded)
+	if err != nil {
+		resp.Diagnostics.AddError("Marshal Error", fmt.Sprintf("Unable to encode the result, got error: %s", err))
+		return
+	}
+
+	data.Result = types.DynamicValue(result)
+	data.Json = types.StringValue(string(compact))
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/tanka/pkg/helm"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaHelmTemplateDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaHelmTemplateDataSource{}
+
+func NewTankaHelmTemplateDataSource() datasource.DataSource {
+	return &TankaHelmTemplateDataSource{}
+}
+
+// TankaHelmTemplateDataSource defines the data source implementation.
+type TankaHelmTemplateDataSource struct {
+	client *Client
+}
+
+// TankaHelmTemplateDataSourceModel describes the data source data model.
+type TankaHelmTemplateDataSourceModel struct {
+	Chart        types.String  `tfsdk:"chart"`
+	Name         types.String  `tfsdk:"name"`
+	BaseDir      types.String  `tfsdk:"base_dir"`
+	Namespace    types.String  `tfsdk:"namespace"`
+	Values       ConfigString  `tfsdk:"values"`
+	ValuesObject types.Dynamic `tfsdk:"values_object"`
+	APIVersions  types.List    `tfsdk:"api_versions"`
+	KubeVersion  types.String  `tfsdk:"kube_version"`
+	IncludeCRDs  types.Bool    `tfsdk:"include_crds"`
+	SkipTests    types.Bool    `tfsdk:"skip_tests"`
+	NoHooks      types.Bool    `tfsdk:"no_hooks"`
+	Manifests    types.Dynamic `tfsdk:"manifests"`
+	Yaml         types.String  `tfsdk:"yaml"`
+}
+
+func (d *TankaHelmTemplateDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_helm_template"
+}
+
+func (d *TankaHelmTemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders a vendored Helm chart with `helm template`, the way `helm.template()` does inside a Tanka environment.",
+
+		Attributes: map[string]schema.Attribute{
+			"chart": schema.StringAttribute{
+				MarkdownDescription: "Directory of the vendored chart, e.g. `tanka/charts/grafana`. Relative paths are resolved against the base directory.",
+				Required:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The release name, available to the chart as `.Release.Name`.",
+				Required:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative `chart` and `file://` paths are resolved against. Overrides the `base_dir` of the provider.",
+				Optional:            true,
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "The namespace of the release, available to the chart as `.Release.Namespace`.",
+				Optional:            true,
+			},
+			"values": schema.StringAttribute{
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Values object passed to the chart, accepting the same sources as `config` of `tanka_release`, such as `jsonencode()`, `file://` or `yaml://`. Conflicts with `values_object`.",
+				Optional:            true,
+			},
+			"values_object": schema.DynamicAttribute{
+				MarkdownDescription: "Values passed to the chart as a native HCL object, without `jsonencode()`. Conflicts with `values`.",
+				Optional:            true,
+			},
+			"api_versions": schema.ListAttribute{
+				MarkdownDescription: "Kubernetes API versions available to the chart as `.Capabilities.APIVersions`, e.g. `monitoring.coreos.com/v1`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"kube_version": schema.StringAttribute{
+				MarkdownDescription: "Kubernetes version available to the chart as `.Capabilities.KubeVersion`.",
+				Optional:            true,
+			},
+			"include_crds": schema.BoolAttribute{
+				MarkdownDescription: "Include the CustomResourceDefinitions of the chart.",
+				Optional:            true,
+			},
+			"skip_tests": schema.BoolAttribute{
+				MarkdownDescription: "Leave out the test manifests of the chart.",
+				Optional:            true,
+			},
+			"no_hooks": schema.BoolAttribute{
+				MarkdownDescription: "Leave out the hook manifests of the chart.",
+				Optional:            true,
+			},
+			"manifests": schema.DynamicAttribute{
+				MarkdownDescription: "The rendered manifests in the order of the chart as a list of objects, typed the same way `jsondecode()` would type them.",
+				Computed:            true,
+			},
+			"yaml": schema.StringAttribute{
+				MarkdownDescription: "The rendered manifests as a multi-document YAML stream.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *TankaHelmTemplateDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaHelmTemplateDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data TankaHelmTemplateDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Values.IsNull() && !data.ValuesObject.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("values_object"), "Conflicting Configuration", "Only one of `values` and `values_object` can be set.")
+	}
+}
+
+func (d *TankaHelmTemplateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaHelmTemplateDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var values string
+	if data.ValuesObject.IsNull() {
+		values, diags = d.client.resolveConfig(ctx, path.Root("values"), stringOrEmptyObject(data.Values), opts)
+	} else {
+		values, diags = resolveConfigObject(path.Root("values_object"), data.ValuesObject)
+	}
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	helm_values, err := parseHelmValues(values)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("values"), "Parse Error", fmt.Sprintf("Unable to parse values as an object, got error: %s", err))
+		return
+	}
+
+	template_opts := helm.TemplateOpts{
+		Values:      helm_values,
+		Namespace:   data.Namespace.ValueString(),
+		KubeVersion: data.KubeVersion.ValueString(),
+		IncludeCRDs: data.IncludeCRDs.ValueBool(),
+		SkipTests:   data.SkipTests.ValueBool(),
+		NoHooks:     data.NoHooks.ValueBool(),
+	}
+	resp.Diagnostics.Append(data.APIVersions.ElementsAs(ctx, &template_opts.APIVersions, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	chart := resolvePath(opts.BaseDir, data.Chart.ValueString())
+	manifests, err := helm.ExecHelm{}.Template(data.Name.ValueString(), chart, template_opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Template Error", fmt.Sprintf("Unable to render helm chart %s, got error: %s", chart, err))
+		return
+	}
+
+	data.Manifests, data.Yaml, diags = manifestOutputs(manifests)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseHelmValues decodes the values object, keeping whole numbers integers.
+// Decoded as float64 they would be passed to helm in exponent notation.
+func parseHelmValues(values string) (map[string]interface{}, error) {
+	decoded, err := decodeJSON(values)
+	if err != nil {
+		return nil, err
+	}
+
+	// null decodes to a nil interface, which isn't a map either
+	numbers, ok := helmNumbers(decoded).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("values must be an object")
+	}
+
+	return numbers, nil
+}
+
+func helmNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = helmNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = helmNumbers(item)
+		}
+	case json.Number:
+		if number, err := value.Int64(); err == nil {
+			return number
+		}
+		number, _ := value.Float64()
+		return number
+	}
+
+	return value
+}
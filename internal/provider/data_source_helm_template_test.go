@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHelmValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   string
+		expected map[string]interface{}
+		err      bool
+	}{
+		{"integers", `{"replicas": 3, "port": 8080}`, map[string]interface{}{"replicas": int64(3), "port": int64(8080)}, false},
+		{"large integer", `{"size": 10000000}`, map[string]interface{}{"size": int64(10000000)}, false},
+		{"float", `{"ratio": 0.5}`, map[string]interface{}{"ratio": 0.5}, false},
+		{"nested", `{"a": {"b": [1, "x", true]}}`, map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{int64(1), "x", true}}}, false},
+		{"list", `[1]`, nil, true},
+		{"invalid", `{`, nil, true},
+		{"null", `null`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseHelmValues(test.values)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
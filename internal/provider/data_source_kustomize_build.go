@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+	"github.com/grafana/tanka/pkg/kustomize"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaKustomizeBuildDataSource{}
+
+func NewTankaKustomizeBuildDataSource() datasource.DataSource {
+	return &TankaKustomizeBuildDataSource{}
+}
+
+// TankaKustomizeBuildDataSource defines the data source implementation.
+type TankaKustomizeBuildDataSource struct {
+	client *Client
+}
+
+// TankaKustomizeBuildDataSourceModel describes the data source data model.
+type TankaKustomizeBuildDataSourceModel struct {
+	Path      types.String  `tfsdk:"path"`
+	BaseDir   types.String  `tfsdk:"base_dir"`
+	Manifests types.Dynamic `tfsdk:"manifests"`
+	Yaml      types.String  `tfsdk:"yaml"`
+}
+
+func (d *TankaKustomizeBuildDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_kustomize_build"
+}
+
+func (d *TankaKustomizeBuildDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Builds a local kustomization with `kustomize build`, the way `kustomize.build()` does inside a Tanka environment.",
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Directory of the kustomization, containing a `kustomization.yaml`. Relative paths are resolved against the base directory.",
+				Required:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory a relative `path` is resolved against. Overrides the `base_dir` of the provider.",
+				Optional:            true,
+			},
+			"manifests": schema.DynamicAttribute{
+				MarkdownDescription: "The built manifests in the order of the kustomize output as a list of objects, typed the same way `jsondecode()` would type them.",
+				Computed:            true,
+			},
+			"yaml": schema.StringAttribute{
+				MarkdownDescription: "The built manifests as a multi-document YAML stream.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *TankaKustomizeBuildDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaKustomizeBuildDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaKustomizeBuildDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kustomization := resolvePath(opts.BaseDir, data.Path.ValueString())
+	built, err := kustomize.ExecKustomize{}.Build(kustomization)
+	if err != nil {
+		resp.Diagnostics.AddError("Build Error", fmt.Sprintf("Unable to build kustomization %s, got error: %s", kustomization, err))
+		return
+	}
+
+	// Empty documents of the stream are decoded as empty manifests
+	manifests := manifest.List{}
+	for _, m := range built {
+		if len(m) > 0 {
+			manifests = append(manifests, m)
+		}
+	}
+
+	data.Manifests, data.Yaml, diags = manifestOutputs(manifests)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaLintDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaLintDataSource{}
+
+const defaultLintPath = "tanka"
+
+// lintFindingAttrTypes describes an entry of the findings list.
+var lintFindingAttrTypes = map[string]attr.Type{
+	"check":   types.StringType,
+	"file":    types.StringType,
+	"line":    types.Int64Type,
+	"column":  types.Int64Type,
+	"message": types.StringType,
+}
+
+func NewTankaLintDataSource() datasource.DataSource {
+	return &TankaLintDataSource{}
+}
+
+// TankaLintDataSource defines the data source implementation.
+type TankaLintDataSource struct {
+	client *Client
+}
+
+// TankaLintDataSourceModel describes the data source data model.
+type TankaLintDataSourceModel struct {
+	Paths          types.List   `tfsdk:"paths"`
+	BaseDir        types.String `tfsdk:"base_dir"`
+	Excludes       types.List   `tfsdk:"excludes"`
+	Lint           types.Bool   `tfsdk:"lint"`
+	FormatCheck    types.Bool   `tfsdk:"format_check"`
+	FailOnFindings types.Bool   `tfsdk:"fail_on_findings"`
+	Passed         types.Bool   `tfsdk:"passed"`
+	Findings       types.List   `tfsdk:"findings"`
+}
+
+func (d *TankaLintDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_lint"
+}
+
+func (d *TankaLintDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lints Jsonnet files and checks their format, like `tk lint` and `tk fmt --test`, without evaluating them.",
+
+		Attributes: map[string]schema.Attribute{
+			"paths": schema.ListAttribute{
+				MarkdownDescription: "Files and directories to check, directories are searched recursively for `.jsonnet` and `.libsonnet` files. Relative paths are resolved against the base directory. Defaults to `[\"tanka\"]`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative paths are resolved against. Overrides the `base_dir` of the provider.",
+				Optional:            true,
+			},
+			"excludes": schema.ListAttribute{
+				MarkdownDescription: "Glob patterns of further files to skip, matched against the path relative to the checked directory. Hidden files and vendored libraries, `[\"**/.*\", \".*\", \"**/vendor/**\", \"vendor/**\"]`, are always skipped.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"lint": schema.BoolAttribute{
+				MarkdownDescription: "Run the Jsonnet linter, which reports problems such as unused variables or unknown fields of the standard library. Defaults to `true`.",
+				Optional:            true,
+			},
+			"format_check": schema.BoolAttribute{
+				MarkdownDescription: "Report files `tk fmt` would change, located at the first line that differs. Defaults to `true`.",
+				Optional:            true,
+			},
+			"fail_on_findings": schema.BoolAttribute{
+				MarkdownDescription: "Fail with an error listing the findings instead of reporting them in `findings`.",
+				Optional:            true,
+			},
+			"passed": schema.BoolAttribute{
+				MarkdownDescription: "Whether no problems were found.",
+				Computed:            true,
+			},
+			"findings": schema.ListNestedAttribute{
+				MarkdownDescription: "The problems found, ordered by file and location.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"check": schema.StringAttribute{
+							MarkdownDescription: "The check reporting the problem, `lint` or `format`.",
+							Computed:            true,
+						},
+						"file": schema.StringAttribute{
+							MarkdownDescription: "The absolute path of the file.",
+							Computed:            true,
+						},
+						"line": schema.Int64Attribute{
+							MarkdownDescription: "The line of the problem, starting at 1.",
+							Computed:            true,
+						},
+						"column": schema.Int64Attribute{
+							MarkdownDescription: "The column of the problem, starting at 1.",
+							Computed:            true,
+						},
+						"message": schema.StringAttribute{
+							MarkdownDescription: "The description of the problem.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *TankaLintDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaLintDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var excludes types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("excludes"), &excludes)...)
+	if excludes.IsNull() || excludes.IsUnknown() {
+		return
+	}
+
+	var patterns []types.String
+	resp.Diagnostics.Append(excludes.ElementsAs(ctx, &patterns, false)...)
+	for i, pattern := range patterns {
+		if pattern.IsNull() || pattern.IsUnknown() {
+			continue
+		}
+		if _, err := compileExcludes(nil, []string{pattern.ValueString()}); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("excludes").AtListIndex(i), "Invalid Exclude", fmt.Sprintf("Unable to parse glob pattern, got error: %s", err))
+		}
+	}
+}
+
+func (d *TankaLintDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaLintDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	paths := []string{defaultLintPath}
+	if !data.Paths.IsNull() {
+		resp.Diagnostics.Append(data.Paths.ElementsAs(ctx, &paths, false)...)
+	}
+	// The patterns add to the default ones
+	var excludes []string
+	if !data.Excludes.IsNull() {
+		resp.Diagnostics.Append(data.Excludes.ElementsAs(ctx, &excludes, false)...)
+	}
+	excludes = append(append([]string{}, defaultLintExcludes...), excludes...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i := range paths {
+		paths[i] = resolvePath(opts.BaseDir, paths[i])
+	}
+
+	var findings []lintFinding
+	if data.Lint.IsNull() || data.Lint.ValueBool() {
+		lint_findings, err := lintJsonnet(paths, excludes)
+		if err != nil {
+			resp.Diagnostics.AddError("Lint Error", fmt.Sprintf("Unable to lint jsonnet files, got error: %s", err))
+			return
+		}
+		findings = append(findings, lint_findings...)
+	}
+	if data.FormatCheck.IsNull() || data.FormatCheck.ValueBool() {
+		format_findings, err := checkFormat(paths, excludes)
+		if err != nil {
+			resp.Diagnostics.AddError("Format Error", fmt.Sprintf("Unable to check the format of jsonnet files, got error: %s", err))
+			return
+		}
+		findings = append(findings, format_findings...)
+	}
+	sortLintFindings(findings)
+
+	if data.FailOnFindings.ValueBool() && len(findings) > 0 {
+		summary := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			summary = append(summary, fmt.Sprintf("  %s: %s", finding.location(), finding.Message))
+		}
+		resp.Diagnostics.AddError("Lint Findings", fmt.Sprintf("Found %d problems in the jsonnet files:\n%s", len(findings), strings.Join(summary, "\n")))
+		return
+	}
+
+	elements := make([]attr.Value, 0, len(findings))
+	for _, finding := range findings {
+		element, diags := types.ObjectValue(lintFindingAttrTypes, map[string]attr.Value{
+			"check":   types.StringValue(finding.Check),
+			"file":    types.StringValue(finding.File),
+			"line":    types.Int64Value(finding.Line),
+			"column":  types.Int64Value(finding.Column),
+			"message": types.StringValue(finding.Message),
+		})
+		resp.Diagnostics.Append(diags...)
+		elements = append(elements, element)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Passed = types.BoolValue(len(findings) == 0)
+	data.Findings, diags = types.ListValue(types.ObjectType{AttrTypes: lintFindingAttrTypes}, elements)
+	resp.Diagnostics.Append(diags...)
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
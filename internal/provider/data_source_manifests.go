@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaManifestsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaManifestsDataSource{}
+
+func NewTankaManifestsDataSource() datasource.DataSource {
+	return &TankaManifestsDataSource{}
+}
+
+// TankaManifestsDataSource defines the data source implementation.
+type TankaManifestsDataSource struct {
+	client *Client
+}
+
+// TankaManifestsDataSourceModel describes the data source data model.
+type TankaManifestsDataSourceModel struct {
+	ConfigInputsModel
+
+	Namespace     types.String        `tfsdk:"namespace"`
+	SourcePath    types.String        `tfsdk:"source_path"`
+	BaseDir       types.String        `tfsdk:"base_dir"`
+	ConfigSources []ConfigSourceModel `tfsdk:"config_source"`
+	Name          types.String        `tfsdk:"name"`
+	Targets       types.List          `tfsdk:"targets"`
+	Manifests     types.Dynamic       `tfsdk:"manifests"`
+	Yaml          types.String        `tfsdk:"yaml"`
+}
+
+func (d *TankaManifestsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_manifests"
+}
+
+func (d *TankaManifestsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := environmentDataSourceAttributes()
+
+	attributes["name"] = schema.StringAttribute{
+		MarkdownDescription: "Name of the inline environment to render, required when the main file returns more than one.",
+		Optional:            true,
+	}
+	attributes["targets"] = schema.ListAttribute{
+		MarkdownDescription: "Only render the manifests matching one of the `kind/name` regular expressions, matched case-insensitively. Prefix an expression with `!` to exclude the matching manifests instead.",
+		ElementType:         types.StringType,
+		Optional:            true,
+	}
+	attributes["manifests"] = schema.DynamicAttribute{
+		MarkdownDescription: "The rendered manifests as a list of objects, typed the same way `jsondecode()` would type them.",
+		Computed:            true,
+	}
+	attributes["yaml"] = schema.StringAttribute{
+		MarkdownDescription: "The rendered manifests as a multi-document YAML stream.",
+		Computed:            true,
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders the manifests of a Tanka environment without applying them, with the same inputs as `tanka_release`.",
+
+		Attributes: attributes,
+
+		Blocks: map[string]schema.Block{
+			"config_source": configSourceDataSourceBlock(),
+		},
+	}
+}
+
+func (d *TankaManifestsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaManifestsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateConfigInputs(ctx, req.Config)...)
+}
+
+func (d *TankaManifestsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaManifestsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Namespace = stringOrDefault(data.Namespace, defaultNamespace)
+	data.SourcePath = stringOrDefault(data.SourcePath, defaultSourcePath)
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, data.ConfigSources)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resolved, diags := d.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var targets []string
+	resp.Diagnostics.Append(data.Targets.ElementsAs(ctx, &targets, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	manifests, err := d.client.Show(d.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, "", resolvePath(opts.BaseDir, data.SourcePath.ValueString()), data.Name.ValueString(), targets)
+	if err != nil {
+		resp.Diagnostics.Append(jsonnetErrorDiagnostic(err, ""))
+		return
+	}
+
+	data.Manifests, data.Yaml, diags = manifestOutputs(manifests)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TankaReleaseStatusDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &TankaReleaseStatusDataSource{}
+
+// objectConditionAttrTypes describes an entry of the conditions of an object.
+var objectConditionAttrTypes = map[string]attr.Type{
+	"type":    types.StringType,
+	"status":  types.StringType,
+	"reason":  types.StringType,
+	"message": types.StringType,
+}
+
+// objectStatusAttrTypes describes an entry of the objects list.
+var objectStatusAttrTypes = map[string]attr.Type{
+	"api_version":         types.StringType,
+	"kind":                types.StringType,
+	"namespace":           types.StringType,
+	"name":                types.StringType,
+	"ready":               types.BoolType,
+	"generation":          types.Int64Type,
+	"observed_generation": types.Int64Type,
+	"replicas":            types.Int64Type,
+	"ready_replicas":      types.Int64Type,
+	"images":              types.ListType{ElemType: types.StringType},
+	"conditions":          types.ListType{ElemType: types.ObjectType{AttrTypes: objectConditionAttrTypes}},
+}
+
+func NewTankaReleaseStatusDataSource() datasource.DataSource {
+	return &TankaReleaseStatusDataSource{}
+}
+
+// TankaReleaseStatusDataSource defines the data source implementation.
+type TankaReleaseStatusDataSource struct {
+	client *Client
+}
+
+// TankaReleaseStatusDataSourceModel describes the data source data model.
+type TankaReleaseStatusDataSourceModel struct {
+	ConfigInputsModel
+
+	Namespace     types.String        `tfsdk:"namespace"`
+	SourcePath    types.String        `tfsdk:"source_path"`
+	BaseDir       types.String        `tfsdk:"base_dir"`
+	ConfigSources []ConfigSourceModel `tfsdk:"config_source"`
+	Name          types.String        `tfsdk:"name"`
+	Healthy       types.Bool          `tfsdk:"healthy"`
+	Objects       types.List          `tfsdk:"objects"`
+}
+
+func (d *TankaReleaseStatusDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "tanka_release_status"
+}
+
+func (d *TankaReleaseStatusDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := environmentDataSourceAttributes()
+
+	attributes["name"] = schema.StringAttribute{
+		MarkdownDescription: "Name of the inline environment, required when the main file returns more than one.",
+		Optional:            true,
+	}
+	attributes["healthy"] = schema.BoolAttribute{
+		MarkdownDescription: "Whether all objects are ready, false when no objects are found.",
+		Computed:            true,
+	}
+	attributes["objects"] = schema.ListNestedAttribute{
+		MarkdownDescription: "The live objects labelled with the environment, ordered by namespace, kind and name.",
+		Computed:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"api_version": schema.StringAttribute{
+					MarkdownDescription: "The API version of the object.",
+					Computed:            true,
+				},
+				"kind": schema.StringAttribute{
+					MarkdownDescription: "The kind of the object.",
+					Computed:            true,
+				},
+				"namespace": schema.StringAttribute{
+					MarkdownDescription: "The namespace of the object, empty for cluster-wide objects.",
+					Computed:            true,
+				},
+				"name": schema.StringAttribute{
+					MarkdownDescription: "The name of the object.",
+					Computed:            true,
+				},
+				"ready": schema.BoolAttribute{
+					MarkdownDescription: "Whether the object is ready. Workloads are ready once the latest generation is rolled out to all replicas, jobs once they are complete and other objects when their `Ready` or `Available` condition is true. Objects without any of these are ready once they exist.",
+					Computed:            true,
+				},
+				"generation": schema.Int64Attribute{
+					MarkdownDescription: "The `metadata.generation` of the object.",
+					Computed:            true,
+				},
+				"observed_generation": schema.Int64Attribute{
+					MarkdownDescription: "The `status.observedGeneration` of the object, the generation last seen by its controller.",
+					Computed:            true,
+				},
+				"replicas": schema.Int64Attribute{
+					MarkdownDescription: "The desired number of replicas of a workload.",
+					Computed:            true,
+				},
+				"ready_replicas": schema.Int64Attribute{
+					MarkdownDescription: "The number of ready replicas of a workload.",
+					Computed:            true,
+				},
+				"images": schema.ListAttribute{
+					MarkdownDescription: "The sorted container images of a workload or pod.",
+					ElementType:         types.StringType,
+					Computed:            true,
+				},
+				"conditions": schema.ListNestedAttribute{
+					MarkdownDescription: "The `status.conditions` of the object.",
+					Computed:            true,
+					NestedObject: schema.NestedAttributeObject{
+						Attributes: map[string]schema.Attribute{
+							"type": schema.StringAttribute{
+								MarkdownDescription: "The type of the condition.",
+								Computed:            true,
+							},
+							"status": schema.StringAttribute{
+								MarkdownDescription: "The status of the condition, `True`, `False` or `Unknown`.",
+								Computed:            true,
+							},
+							"reason": schema.StringAttribute{
+								MarkdownDescription: "The reason of the last transition.",
+								Computed:            true,
+							},
+							"message": schema.StringAttribute{
+								MarkdownDescription: "The message of the last transition.",
+								Computed:            true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reports the health of the live objects of a Tanka environment, found by their `tanka.dev/environment` label.",
+
+		Attributes: attributes,
+
+		Blocks: map[string]schema.Block{
+			"config_source": configSourceDataSourceBlock(),
+		},
+	}
+}
+
+func (d *TankaReleaseStatusDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *TankaReleaseStatusDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateConfigInputs(ctx, req.Config)...)
+}
+
+func (d *TankaReleaseStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TankaReleaseStatusDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Namespace = stringOrDefault(data.Namespace, defaultNamespace)
+	data.SourcePath = stringOrDefault(data.SourcePath, defaultSourcePath)
+
+	opts, diags := d.client.parseOpts(ctx, data.BaseDir, data.ConfigSources)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The environment is only evaluated for its name and cluster
+	resolved, diags := d.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objects, err := d.client.Status(d.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, "", resolvePath(opts.BaseDir, data.SourcePath.ValueString()), data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Status Error", fmt.Sprintf("Unable to fetch the live objects of tanka package, got error: %s", err))
+		return
+	}
+
+	statuses := make([]objectStatus, 0, len(objects))
+	for _, object := range objects {
+		statuses = append(statuses, newObjectStatus(object))
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		if statuses[i].Kind != statuses[j].Kind {
+			return statuses[i].Kind < statuses[j].Kind
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	elements := make([]attr.Value, 0, len(statuses))
+	for _, status := range statuses {
+		element, diags := objectStatusValue(ctx, status)
+		resp.Diagnostics.Append(diags...)
+		elements = append(elements, element)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Healthy = types.BoolValue(releaseHealthy(statuses))
+	data.Objects, diags = types.ListValue(types.ObjectType{AttrTypes: objectStatusAttrTypes}, elements)
+	resp.Diagnostics.Append(diags...)
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// objectStatusValue converts the status of an object into an entry of the
+// objects list.
+func objectStatusValue(ctx context.Context, status objectStatus) (types.Object, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	conditions := make([]attr.Value, 0, len(status.Conditions))
+	for _, condition := range status.Conditions {
+		element, d := types.ObjectValue(objectConditionAttrTypes, map[string]attr.Value{
+			"type":    types.StringValue(condition.Type),
+			"status":  types.StringValue(condition.Status),
+			"reason":  types.StringValue(condition.Reason),
+			"message": types.StringValue(condition.Message),
+		})
+		diags.Append(d...)
+		conditions = append(conditions, element)
+	}
+
+	condition_list, d := types.ListValue(types.ObjectType{AttrTypes: objectConditionAttrTypes}, conditions)
+	diags.Append(d...)
+	images, d := types.ListValueFrom(ctx, types.StringType, status.Images)
+	diags.Append(d...)
+	if diags.HasError() {
+		return types.ObjectNull(objectStatusAttrTypes), diags
+	}
+
+	object, d := types.ObjectValue(objectStatusAttrTypes, map[string]attr.Value{
+		"api_version":         types.StringValue(status.APIVersion),
+		"kind":                types.StringValue(status.Kind),
+		"namespace":           types.StringValue(status.Namespace),
+		"name":                types.StringValue(status.Name),
+		"ready":               types.BoolValue(status.Ready),
+		"generation":          types.Int64PointerValue(status.Generation),
+		"observed_generation": types.Int64PointerValue(status.ObservedGeneration),
+		"replicas":            types.Int64PointerValue(status.Replicas),
+		"ready_replicas":      types.Int64PointerValue(status.ReadyReplicas),
+		"images":              images,
+		"conditions":          condition_list,
+	})
+	diags.Append(d...)
+
+	return object, diags
+}
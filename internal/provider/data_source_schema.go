@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	defaultNamespace  = "default"
+	defaultSourcePath = "tanka/environments/default"
+)
+
+// environmentDataSourceAttributes are the attributes of data sources that
+// evaluate a tanka environment the same way `tanka_release` does.
+func environmentDataSourceAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"namespace": schema.StringAttribute{
+			MarkdownDescription: "The Kubernetes namespace passed to the environment. Defaults to `default`.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"source_path": schema.StringAttribute{
+			MarkdownDescription: "The location of the Tanka main file. Relative paths are resolved against the base directory. Defaults to `tanka/environments/default`.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"base_dir": schema.StringAttribute{
+			MarkdownDescription: "Directory relative `source_path` and `file://` paths are resolved against. Overrides the `base_dir` of the provider.",
+			Optional:            true,
+		},
+		"config": schema.StringAttribute{
+			MarkdownDescription: "Configuration object passed as `tf_config`, accepting the same sources as `config` of `tanka_release`. Defaults to the empty object.",
+			CustomType:          ConfigStringType{},
+			Optional:            true,
+		},
+		"config_override": schema.StringAttribute{
+			MarkdownDescription: "Configuration override object passed as `tf_config_override`, accepting the same sources as `config` of `tanka_release`. Defaults to the empty object.",
+			CustomType:          ConfigStringType{},
+			Optional:            true,
+		},
+		"config_object": schema.DynamicAttribute{
+			MarkdownDescription: "Configuration object given as a native HCL object. Conflicts with `config` and `config_layers`.",
+			Optional:            true,
+		},
+		"config_override_object": schema.DynamicAttribute{
+			MarkdownDescription: "Configuration override object given as a native HCL object. Conflicts with `config_override` and `config_layers`.",
+			Optional:            true,
+		},
+		"config_layers": schema.ListAttribute{
+			MarkdownDescription: "Ordered list of configuration objects merged with the semantics of `std.mergePatch()`, see `config_layers` of `tanka_release`. Conflicts with `config`, `config_override`, `config_object` and `config_override_object`.",
+			ElementType:         ConfigStringType{},
+			Optional:            true,
+		},
+	}
+}
+
+// configSourceDataSourceBlock is the data source variant of the
+// `config_source` block of `tanka_release`.
+func configSourceDataSourceBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Settings used when fetching a remote `http://` or `https://` config source, see `config_source` of `tanka_release`.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"url": schema.StringAttribute{
+					MarkdownDescription: "The URL of the remote source the settings apply to.",
+					Required:            true,
+				},
+				"bearer_token": schema.StringAttribute{
+					MarkdownDescription: "Token sent in the `Authorization` header as bearer authentication. Conflicts with `username`.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"username": schema.StringAttribute{
+					MarkdownDescription: "Username for basic authentication. Conflicts with `bearer_token`.",
+					Optional:            true,
+				},
+				"password": schema.StringAttribute{
+					MarkdownDescription: "Password for basic authentication.",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"headers": schema.MapAttribute{
+					MarkdownDescription: "Additional headers sent with the request.",
+					ElementType:         types.StringType,
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"ca_certificate": schema.StringAttribute{
+					MarkdownDescription: "PEM encoded CA bundle trusted in addition to the system certificates.",
+					Optional:            true,
+				},
+				"timeout": schema.StringAttribute{
+					MarkdownDescription: "Timeout of each request as a positive duration, e.g. `10s`. Defaults to `30s`.",
+					Optional:            true,
+				},
+				"retries": schema.Int64Attribute{
+					MarkdownDescription: "Number of times a request is retried on network errors, `429` and `5xx` responses. Defaults to `0`.",
+					Optional:            true,
+				},
+				"sha256": schema.StringAttribute{
+					MarkdownDescription: "Expected SHA-256 checksum of the fetched document in hex.",
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// stringOrDefault returns the value, or the default when it isn't set.
+func stringOrDefault(value types.String, fallback string) types.String {
+	if value.IsNull() || value.ValueString() == "" {
+		return types.StringValue(fallback)
+	}
+
+	return value
+}
@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/grafana/tanka/pkg/tanka"
+)
+
+// exportManifestFile is the file tanka maps the exported files to their
+// environment in.
+const exportManifestFile = "manifest.json"
+
+// exportedFile is a manifest rendered to a file of an export.
+type exportedFile struct {
+	Env     string
+	Content []byte
+}
+
+// RenderExport evaluates the environments found at the source path and renders
+// their manifests to files with `tk export`, keyed by the path relative to the
+// output directory. The files are staged in a temporary directory, so the
+// output directory is only changed once all environments are rendered. It
+// also returns the exported environments, named as in the manifest file.
+func (c *Client) RenderExport(api_server, namespace, config, config_override, source_path string, opts tanka.ExportEnvOpts) (files map[string]exportedFile, envs map[string]bool, err error) {
+	base_opts := createBaseOpts(api_server, namespace, config, config_override, "")
+	opts.Opts = base_opts.Opts
+
+	root, err := jpath.FindRoot(source_path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found, err := tanka.FindEnvs(source_path, tanka.FindOpts{JsonnetOpts: opts.Opts.JsonnetOpts, Selector: opts.Selector})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil, fmt.Errorf("no environments found in %s", source_path)
+	}
+
+	envs = map[string]bool{}
+	for _, env := range found {
+		envs[env.Metadata.Namespace] = true
+		if env.Metadata.Namespace, err = exportEnvPath(root, env.Metadata.Namespace); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	staging, err := os.MkdirTemp("", "tanka-export-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	opts.MergeStrategy = tanka.ExportMergeStrategyNone
+	opts.MergeDeletedEnvs = nil
+	if err := tanka.ExportEnvironments(found, staging, &opts); err != nil {
+		return nil, nil, err
+	}
+
+	file_envs, err := readExportManifest(staging)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files = make(map[string]exportedFile, len(file_envs))
+	for file, env := range file_envs {
+		content, err := os.ReadFile(filepath.Join(staging, file))
+		if err != nil {
+			return nil, nil, err
+		}
+		files[file] = exportedFile{Env: env, Content: content}
+	}
+
+	return files, envs, nil
+}
+
+// exportEnvPath returns the path tanka loads the environment from when
+// exporting. Tanka finds the project root from the path relative to the
+// working directory and then joins it to the root, so it only exports from the
+// project root. Climbing to the file system root first makes the path resolve
+// to the environment from both, without changing the working directory.
+func exportEnvPath(root, env_path string) (string, error) {
+	workdir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	absolute := filepath.Join(root, env_path)
+	separator := string(filepath.Separator)
+	depth := max(strings.Count(workdir, separator), strings.Count(root, separator))
+
+	return strings.Repeat(".."+separator, depth) + strings.TrimPrefix(absolute[len(filepath.VolumeName(absolute)):], separator), nil
+}
+
+// writeExport writes the rendered files to the output directory with the merge
+// strategy of tanka and records them in the manifest file. The files of the
+// previous export may be replaced, the ones that are not written again are
+// removed after the new files are in place.
+func writeExport(output_dir string, files map[string]exportedFile, envs map[string]bool, strategy tanka.ExportMergeStrategy, previous []string) error {
+	replaced := map[string]bool{}
+	for _, file := range previous {
+		replaced[file] = true
+	}
+
+	file_envs, err := readExportManifest(output_dir)
+	if err != nil {
+		return err
+	}
+	if strategy == tanka.ExportMergeStrategyReplaceEnvs {
+		for file, env := range file_envs {
+			if envs[env] {
+				replaced[file] = true
+			}
+		}
+	}
+
+	if strategy == tanka.ExportMergeStrategyNone {
+		empty, err := exportDirEmpty(output_dir, replaced)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return fmt.Errorf("output dir `%s` not empty, set merge_strategy to export to it anyway", output_dir)
+		}
+	}
+
+	for file := range files {
+		if replaced[file] {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(output_dir, file)); err == nil {
+			return fmt.Errorf("file '%s' already exists", filepath.Join(output_dir, file))
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	for file, exported := range files {
+		file_path := filepath.Join(output_dir, file)
+		if err := os.MkdirAll(filepath.Dir(file_path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file_path, exported.Content, 0644); err != nil {
+			return err
+		}
+		file_envs[file] = exported.Env
+	}
+	if err := writeExportManifest(output_dir, file_envs); err != nil {
+		return err
+	}
+
+	var stale []string
+	for file := range replaced {
+		if _, ok := files[file]; !ok {
+			stale = append(stale, file)
+		}
+	}
+
+	return removeExportedFiles(output_dir, stale)
+}
+
+// exportDirEmpty tells whether the output directory holds no files apart from
+// the ignored ones and the manifest file. A missing directory is empty.
+func exportDirEmpty(output_dir string, ignored map[string]bool) (empty bool, err error) {
+	empty = true
+	err = filepath.WalkDir(output_dir, func(file_path string, entry fs.DirEntry, err error) error {
+		if file_path == output_dir && errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		} else if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		file, err := filepath.Rel(output_dir, file_path)
+		if err != nil {
+			return err
+		}
+		if file != exportManifestFile && !ignored[file] {
+			empty = false
+			return fs.SkipAll
+		}
+		return nil
+	})
+
+	return empty, err
+}
+
+// readExportManifest reads the file to environment mapping of an export
+// directory, a missing file is an empty mapping.
+func readExportManifest(output_dir string) (file_envs map[string]string, err error) {
+	file_envs = map[string]string{}
+
+	raw, err := os.ReadFile(filepath.Join(output_dir, exportManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return file_envs, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &file_envs); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", exportManifestFile, err)
+	}
+
+	return file_envs, nil
+}
+
+// writeExportManifest writes the file to environment mapping of an export
+// directory, the manifest file is removed when the mapping is empty.
+func writeExportManifest(output_dir string, file_envs map[string]string) error {
+	manifest_path := filepath.Join(output_dir, exportManifestFile)
+	if len(file_envs) == 0 {
+		if err := os.Remove(manifest_path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(file_envs, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(manifest_path, raw, 0644)
+}
+
+// hashExportedFile returns the SHA-256 hash of the file content in hex.
+func hashExportedFile(file string) (string, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return hashExportContent(raw), nil
+}
+
+// hashExportContent returns the SHA-256 hash of the content in hex.
+func hashExportContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// removeExportedFiles removes the files from the output directory, together
+// with their entries of the manifest file and the directories left empty. The
+// manifest file is removed once it has no entries left.
+func removeExportedFiles(output_dir string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	file_envs, err := readExportManifest(output_dir)
+	if err != nil {
+		return err
+	}
+
+	// Deeper directories are emptied first
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, file := range files {
+		file_path := filepath.Join(output_dir, file)
+		if err := os.Remove(file_path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		delete(file_envs, file)
+
+		for dir := filepath.Dir(file_path); strings.HasPrefix(dir, output_dir+string(filepath.Separator)); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+
+	return writeExportManifest(output_dir, file_envs)
+}
+
+// exportedFileNames returns the file names of the hashes recorded in state.
+func exportedFileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for file := range files {
+		names = append(names, file)
+	}
+
+	return names
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/tanka"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for file, content := range files {
+		file_path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(file_path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file_path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listTestFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(file_path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		file, err := filepath.Rel(dir, file_path)
+		files = append(files, file)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestRenderExport(t *testing.T) {
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{
+		"jsonnetfile.json":                  `{"version": 1}`,
+		"environments/blue/spec.json":       `{"apiVersion": "tanka.dev/v1alpha1", "kind": "Environment", "metadata": {"name": "blue", "labels": {"color": "blue"}}, "spec": {"namespace": "blue"}}`,
+		"environments/blue/main.jsonnet":    `{ config: { apiVersion: 'v1', kind: 'ConfigMap', metadata: { name: 'settings' } } }`,
+		"environments/green/spec.json":      `{"apiVersion": "tanka.dev/v1alpha1", "kind": "Environment", "metadata": {"name": "green", "labels": {"color": "green"}}, "spec": {"namespace": "green"}}`,
+		"environments/green/main.jsonnet":   `{ config: { apiVersion: 'v1', kind: 'ConfigMap', metadata: { name: 'settings' } } }`,
+		"environments/unrelated/README.txt": ``,
+	})
+
+	workdir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &Client{}
+	files, envs, err := client.RenderExport("", "default", "{}", "{}", filepath.Join(project, "environments"), tanka.ExportEnvOpts{
+		Format:    "{{env.spec.namespace}}/{{.kind}}-{{.metadata.name}}",
+		Extension: "yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if current, err := os.Getwd(); err != nil || current != workdir {
+		t.Errorf("expected the working directory to stay %s, got %s (%v)", workdir, current, err)
+	}
+
+	var names []string
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	expected := []string{filepath.Join("blue", "ConfigMap-settings.yaml"), filepath.Join("green", "ConfigMap-settings.yaml")}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected files %v, got %v", expected, names)
+	}
+
+	blue := files[filepath.Join("blue", "ConfigMap-settings.yaml")]
+	if blue.Env != filepath.Join("environments", "blue", "main.jsonnet") {
+		t.Errorf("expected the environment of the file to be environments/blue/main.jsonnet, got %s", blue.Env)
+	}
+	if !strings.Contains(string(blue.Content), "namespace: blue") {
+		t.Errorf("expected the manifest to be in the blue namespace, got:\n%s", blue.Content)
+	}
+	if !reflect.DeepEqual(envs, map[string]bool{filepath.Join("environments", "blue", "main.jsonnet"): true, filepath.Join("environments", "green", "main.jsonnet"): true}) {
+		t.Errorf("unexpected exported environments %v", envs)
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	rendered := func(files ...string) map[string]exportedFile {
+		result := map[string]exportedFile{}
+		for _, file := range files {
+			result[file] = exportedFile{Env: "environments/blue", Content: []byte("new " + file)}
+		}
+		return result
+	}
+	blue := map[string]bool{"environments/blue": true}
+
+	t.Run("replaces the previous export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"a.yaml":          "old a",
+			"sub/b.yaml":      "old b",
+			"other.yaml":      "other",
+			"manifest.json":   `{"a.yaml": "environments/blue", "sub/b.yaml": "environments/blue", "other.yaml": "environments/other"}`,
+			"unmanaged/c.txt": "unmanaged",
+		})
+
+		err := writeExport(dir, rendered("a.yaml", "c.yaml"), blue, tanka.ExportMergeStrategyFailConflicts, []string{"a.yaml", "sub/b.yaml"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"a.yaml", "c.yaml", "manifest.json", "other.yaml", "unmanaged/c.txt"}
+		if actual := listTestFiles(t, dir); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected files %v, got %v", expected, actual)
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "a.yaml")); string(content) != "new a.yaml" {
+			t.Errorf("expected a.yaml to be replaced, got %s", content)
+		}
+
+		file_envs, err := readExportManifest(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected_envs := map[string]string{"a.yaml": "environments/blue", "c.yaml": "environments/blue", "other.yaml": "environments/other"}
+		if !reflect.DeepEqual(file_envs, expected_envs) {
+			t.Errorf("expected manifest %v, got %v", expected_envs, file_envs)
+		}
+	})
+
+	t.Run("conflict keeps the previous export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"a.yaml":        "old a",
+			"other.yaml":    "other",
+			"manifest.json": `{"a.yaml": "environments/blue", "other.yaml": "environments/other"}`,
+		})
+
+		err := writeExport(dir, rendered("b.yaml", "other.yaml"), blue, tanka.ExportMergeStrategyFailConflicts, []string{"a.yaml"})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected a conflict, got %v", err)
+		}
+
+		expected := []string{"a.yaml", "manifest.json", "other.yaml"}
+		if actual := listTestFiles(t, dir); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected files %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("replace-envs replaces the files of the environments", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"a.yaml":        "old a",
+			"b.yaml":        "old b",
+			"other.yaml":    "other",
+			"manifest.json": `{"a.yaml": "environments/blue", "b.yaml": "environments/blue", "other.yaml": "environments/other"}`,
+		})
+
+		if err := writeExport(dir, rendered("a.yaml"), blue, tanka.ExportMergeStrategyReplaceEnvs, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"a.yaml", "manifest.json", "other.yaml"}
+		if actual := listTestFiles(t, dir); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected files %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("empty directory apart from the previous export", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestFiles(t, dir, map[string]string{
+			"a.yaml":        "old a",
+			"manifest.json": `{"a.yaml": "environments/blue"}`,
+		})
+
+		if err := writeExport(dir, rendered("b.yaml"), blue, tanka.ExportMergeStrategyNone, []string{"a.yaml"}); err != nil {
+			t.Fatal(err)
+		}
+		if actual := listTestFiles(t, dir); !reflect.DeepEqual(actual, []string{"b.yaml", "manifest.json"}) {
+			t.Errorf("expected b.yaml and manifest.json, got %v", actual)
+		}
+
+		writeTestFiles(t, dir, map[string]string{"unmanaged.txt": ""})
+		err := writeExport(dir, rendered("c.yaml"), blue, tanka.ExportMergeStrategyNone, []string{"b.yaml"})
+		if err == nil || !strings.Contains(err.Error(), "not empty") {
+			t.Fatalf("expected the directory not to be empty, got %v", err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "export")
+
+		if err := writeExport(dir, rendered("a/b.yaml"), blue, tanka.ExportMergeStrategyNone, nil); err != nil {
+			t.Fatal(err)
+		}
+		if actual := listTestFiles(t, dir); !reflect.DeepEqual(actual, []string{"a/b.yaml", "manifest.json"}) {
+			t.Errorf("expected a/b.yaml and manifest.json, got %v", actual)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &JsonnetEvaluateFunction{}
+var _ function.Function = &JsonnetEvaluateFileFunction{}
+
+// jsonnetTLAsParameter is the parameter passing top-level arguments to the
+// evaluated Jsonnet.
+var jsonnetTLAsParameter = function.DynamicParameter{
+	Name:                "tlas",
+	MarkdownDescription: "Object of top-level arguments, each value is passed as Jsonnet code the same way `jsonencode()` would encode it. `null` when there are none.",
+	AllowNullValue:      true,
+}
+
+func NewJsonnetEvaluateFunction() function.Function {
+	return &JsonnetEvaluateFunction{}
+}
+
+// JsonnetEvaluateFunction defines the function implementation.
+type JsonnetEvaluateFunction struct{}
+
+func (f *JsonnetEvaluateFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jsonnet_evaluate"
+}
+
+func (f *JsonnetEvaluateFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Evaluates a Jsonnet snippet with the native functions of Tanka",
+		MarkdownDescription: "Evaluates a Jsonnet snippet with the Jsonnet VM of Tanka, including native functions such as `std.native('parseYaml')` and `std.native('manifestYamlFromJson')`, and returns the result typed the same way `jsondecode()` would type it. Relative imports are resolved against the working directory of Terraform, inside a Tanka project the `lib` and `vendor` directories of the project are searched as well. Requires Terraform 1.8 or later.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "snippet",
+				MarkdownDescription: "The Jsonnet to evaluate.",
+			},
+			jsonnetTLAsParameter,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *JsonnetEvaluateFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var snippet string
+	var tlas types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &snippet, &tlas))
+	if resp.Error != nil {
+		return
+	}
+
+	filename := filepath.Join(resolvePath("", "."), snippetFilename)
+	result, err := runJsonnetFunction(filename, snippet, tlas)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
+
+func NewJsonnetEvaluateFileFunction() function.Function {
+	return &JsonnetEvaluateFileFunction{}
+}
+
+// JsonnetEvaluateFileFunction defines the function implementation.
+type JsonnetEvaluateFileFunction struct{}
+
+func (f *JsonnetEvaluateFileFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jsonnet_evaluate_file"
+}
+
+func (f *JsonnetEvaluateFileFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Evaluates a Jsonnet file with the native functions of Tanka",
+		MarkdownDescription: "Evaluates a Jsonnet file with the Jsonnet VM of Tanka, including native functions such as `std.native('parseYaml')` and `std.native('manifestYamlFromJson')`, and returns the result typed the same way `jsondecode()` would type it. Inside a Tanka project the `lib` and `vendor` directories of the project are searched for imports. Requires Terraform 1.8 or later.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "The Jsonnet file to evaluate, a directory evaluates its `main.jsonnet`. Relative paths are resolved against the working directory of Terraform, prefix them with `path.module` to make them relative to the calling module.",
+			},
+			jsonnetTLAsParameter,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *JsonnetEvaluateFileFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var file string
+	var tlas types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &file, &tlas))
+	if resp.Error != nil {
+		return
+	}
+
+	filename := resolvePath("", file)
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		filename = filepath.Join(filename, jpath.DefaultEntrypoint)
+	}
+
+	result, err := runJsonnetFunction(filename, "", tlas)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
+
+// runJsonnetFunction evaluates the file, or the snippet when it is set, with
+// the top-level arguments and converts the result.
+func runJsonnetFunction(filename, snippet string, tlas types.Dynamic) (types.Dynamic, *function.FuncError) {
+	opts := jsonnet.Opts{}
+
+	tla_values, err := attrToInterface(tlas)
+	if err != nil {
+		return types.DynamicNull(), function.NewArgumentFuncError(1, fmt.Sprintf("Unable to convert top-level arguments, got error: %s", err))
+	}
+	if tla_values != nil {
+		tla_object, ok := tla_values.(map[string]interface{})
+		if !ok {
+			return types.DynamicNull(), function.NewArgumentFuncError(1, "Top-level arguments have to be an object.")
+		}
+
+		for name, value := range tla_object {
+			code, err := json.Marshal(value)
+			if err != nil {
+				return types.DynamicNull(), function.NewArgumentFuncError(1, fmt.Sprintf("Unable to encode top-level argument %s, got error: %s", name, err))
+			}
+			opts.TLACode.Set(name, string(code))
+		}
+	}
+
+	raw, err := evaluateJsonnet(filename, snippet, "", opts)
+	if err != nil {
+		return types.DynamicNull(), function.NewFuncError(fmt.Sprintf("Unable to evaluate jsonnet, got error: %s", err))
+	}
+
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return types.DynamicNull(), function.NewFuncError(fmt.Sprintf("Unable to parse the result, got error: %s", err))
+	}
+
+	result, err := interfaceToAttr(decoded)
+	if err != nil {
+		return types.DynamicNull(), function.NewFuncError(fmt.Sprintf("Unable to convert the result, got error: %s", err))
+	}
+
+	// A null result is already a dynamic value
+	if dynamic, ok := result.(types.Dynamic); ok {
+		return dynamic, nil
+	}
+
+	return types.DynamicValue(result), nil
+}
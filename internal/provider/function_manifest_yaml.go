@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+	"github.com/grafana/tanka/pkg/process"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ManifestYamlFunction{}
+
+func NewManifestYamlFunction() function.Function {
+	return &ManifestYamlFunction{}
+}
+
+// ManifestYamlFunction defines the function implementation.
+type ManifestYamlFunction struct{}
+
+func (f *ManifestYamlFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "manifest_yaml"
+}
+
+func (f *ManifestYamlFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Encodes Kubernetes manifests as a YAML stream like tk show",
+		MarkdownDescription: "Encodes Kubernetes manifests as a multi-document YAML stream, exactly the way `tk show` prints the manifests of an environment. Manifests nested in lists and objects are extracted, `List` kinds are replaced by their items and the manifests are sorted in the order Tanka applies them. Requires Terraform 1.8 or later.",
+
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "manifests",
+				MarkdownDescription: "List of manifests, each an object with at least `apiVersion`, `kind` and `metadata.name`. Nested lists and objects are walked the same way as the output of an environment.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ManifestYamlFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var manifests types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &manifests))
+	if resp.Error != nil {
+		return
+	}
+
+	value, err := attrToInterface(manifests)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to convert manifests, got error: %s", err))
+		return
+	}
+
+	// Numbers are decoded as float64 the same way Tanka decodes the output of
+	// the Jsonnet VM, so they are encoded alike
+	raw, err := json.Marshal(value)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to encode manifests, got error: %s", err))
+		return
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to decode manifests, got error: %s", err))
+		return
+	}
+
+	switch decoded.(type) {
+	case []interface{}, map[string]interface{}:
+	default:
+		resp.Error = function.NewArgumentFuncError(0, "Manifests have to be a list or an object.")
+		return
+	}
+
+	list, err := extractManifests(decoded)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to extract manifests, got error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, list.String()))
+}
+
+// extractManifests collects the Kubernetes manifests of the value the same way
+// Tanka processes the output of an environment: manifests are extracted from
+// nested lists and objects, `List` kinds are unwrapped and the result is
+// sorted in the order of `tk show`.
+func extractManifests(value interface{}) (manifest.List, error) {
+	extracted, err := process.Extract(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := process.Unwrap(extracted); err != nil {
+		return nil, err
+	}
+
+	// Sorting by the paths first keeps manifests sort considers equal stable
+	paths := make([]string, 0, len(extracted))
+	for path := range extracted {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	list := make(manifest.List, 0, len(paths))
+	for _, path := range paths {
+		list = append(list, extracted[path])
+	}
+	process.Sort(list)
+
+	return list, nil
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MergePatchFunction{}
+
+func NewMergePatchFunction() function.Function {
+	return &MergePatchFunction{}
+}
+
+// MergePatchFunction defines the function implementation.
+type MergePatchFunction struct{}
+
+func (f *MergePatchFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge_patch"
+}
+
+func (f *MergePatchFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Deep merges two values like std.mergePatch",
+		MarkdownDescription: "Applies `patch` to `target` following [RFC 7386](https://www.rfc-editor.org/rfc/rfc7386), the same semantics as `std.mergePatch()` in Jsonnet. Objects are merged recursively, `null` values in the patch remove the key and any other value, including lists, replaces the target. Unlike `merge()`, nested objects are merged rather than replaced, so the result matches the config a Tanka environment sees. Requires Terraform 1.8 or later.",
+
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "target",
+				MarkdownDescription: "The value to patch, usually an object.",
+				AllowNullValue:      true,
+			},
+			function.DynamicParameter{
+				Name:                "patch",
+				MarkdownDescription: "The patch applied to the target. A value that is not an object replaces the target.",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *MergePatchFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var target, patch types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &target, &patch))
+	if resp.Error != nil {
+		return
+	}
+
+	target_value, err := attrToInterface(target)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to convert target, got error: %s", err))
+		return
+	}
+
+	patch_value, err := attrToInterface(patch)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Unable to convert patch, got error: %s", err))
+		return
+	}
+
+	result, err := interfaceToAttr(mergePatch(target_value, patch_value))
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Unable to convert the merged value, got error: %s", err))
+		return
+	}
+
+	// A null result is already a dynamic value
+	dynamic, ok := result.(types.Dynamic)
+	if !ok {
+		dynamic = types.DynamicValue(result)
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, dynamic))
+}
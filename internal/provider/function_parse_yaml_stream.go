@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ParseYamlStreamFunction{}
+
+func NewParseYamlStreamFunction() function.Function {
+	return &ParseYamlStreamFunction{}
+}
+
+// ParseYamlStreamFunction defines the function implementation.
+type ParseYamlStreamFunction struct{}
+
+func (f *ParseYamlStreamFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_yaml_stream"
+}
+
+func (f *ParseYamlStreamFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Decodes a multi-document YAML stream of Kubernetes manifests",
+		MarkdownDescription: "Decodes a multi-document YAML stream of Kubernetes manifests into a list of objects, the reverse of `manifest_yaml()`. Empty documents are skipped, `List` kinds are replaced by their items and the manifests are sorted in the order `tk show` prints them. Unlike `yamldecode()`, streams with any number of documents are accepted. Requires Terraform 1.8 or later.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "yaml",
+				MarkdownDescription: "The YAML stream, documents separated by `---`. Each document has to be a manifest with at least `apiVersion`, `kind` and `metadata.name`.",
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *ParseYamlStreamFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var stream string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &stream))
+	if resp.Error != nil {
+		return
+	}
+
+	documents, err := decodeYamlStream(stream)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to parse yaml stream, got error: %s", err))
+		return
+	}
+
+	list, err := extractManifests(documents)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to extract manifests, got error: %s", err))
+		return
+	}
+
+	manifests := make([]interface{}, 0, len(list))
+	for _, m := range list {
+		manifests = append(manifests, map[string]interface{}(m))
+	}
+
+	result, err := interfaceToAttr(manifests)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Unable to convert the manifests, got error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, types.DynamicValue(result)))
+}
+
+// decodeYamlStream decodes the non-empty documents of the stream, with numbers
+// decoded as float64 the same way Tanka decodes manifests.
+func decodeYamlStream(stream string) ([]interface{}, error) {
+	documents := []interface{}{}
+
+	decoder := yamlv3.NewDecoder(strings.NewReader(stream))
+	for index := 0; ; index++ {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+
+		if document == nil {
+			continue
+		}
+
+		raw, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, fmt.Errorf("document %d: %w", index, err)
+		}
+		documents = append(documents, decoded)
+	}
+}
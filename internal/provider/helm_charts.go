@@ -0,0 +1,358 @@
+package provider
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grafana/tanka/pkg/helm"
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	helmChartsVerify = "verify"
+	helmChartsVendor = "vendor"
+)
+
+// helmChartsModes are the accepted values of the helm_charts attribute of
+// tanka_release.
+var helmChartsModes = []string{helmChartsVerify, helmChartsVendor}
+
+// chartsVendoring serializes vendoring, as the releases of a project share
+// its charts directory.
+var chartsVendoring sync.Mutex
+
+// syncHelmCharts makes sure the charts required by the chartfile.yaml of the
+// environment at the source path are vendored. With the verify mode missing
+// or outdated charts are an error, with the vendor mode they are pulled.
+func syncHelmCharts(source_path, mode string) error {
+	if mode == helmChartsVendor {
+		chartsVendoring.Lock()
+		defer chartsVendoring.Unlock()
+	}
+
+	charts, dir, outdated, err := outdatedHelmCharts(source_path)
+	if err != nil {
+		return err
+	}
+	if len(outdated) == 0 {
+		return nil
+	}
+
+	if mode != helmChartsVendor {
+		return fmt.Errorf("the charts %s of %s are not vendored, run `tk tool charts vendor` or set `helm_charts` to `vendor`", joinRequirements(outdated), charts.ManifestFile())
+	}
+
+	charts.Helm = localHelm{Helm: charts.Helm, dir: dir}
+	if err := charts.Vendor(false); err != nil {
+		return fmt.Errorf("vendoring charts: %w", err)
+	}
+
+	return nil
+}
+
+// outdatedHelmCharts loads the chartfile.yaml closest to the environment at
+// the source path and returns the required charts that are missing or
+// vendored in another version, together with the directory of the chartfile.
+func outdatedHelmCharts(source_path string) (charts *helm.Charts, dir string, outdated helm.Requirements, err error) {
+	dir, err = findChartfile(source_path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	charts, err = helm.LoadChartfile(dir)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("loading %s: %w", helm.Filename, err)
+	}
+
+	outdated, err = outdatedCharts(charts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return charts, dir, outdated, nil
+}
+
+// findChartfile returns the directory of the chartfile.yaml closest to the
+// environment, looking up to the root of the Tanka project.
+func findChartfile(source_path string) (string, error) {
+	dir := source_path
+	if info, err := os.Stat(source_path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(source_path)
+	}
+
+	root, err := jpath.FindRoot(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, helm.Filename)); err == nil {
+			return dir, nil
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return "", fmt.Errorf("no %s found between %s and the project root %s", helm.Filename, source_path, root)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// outdatedCharts returns the required charts that are missing from the charts
+// directory or vendored in another version.
+func outdatedCharts(charts *helm.Charts) (outdated helm.Requirements, err error) {
+	for _, requirement := range charts.Manifest.Requires {
+		chart_dir := requirement.Directory
+		if chart_dir == "" {
+			_, chart_dir, _ = strings.Cut(requirement.Chart, "/")
+		}
+
+		raw, err := os.ReadFile(filepath.Join(charts.ChartDir(), chart_dir, "Chart.yaml"))
+		if errors.Is(err, fs.ErrNotExist) {
+			outdated = append(outdated, requirement)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var chart struct {
+			Version string `json:"version"`
+		}
+		if err := yaml.Unmarshal(raw, &chart); err != nil {
+			return nil, fmt.Errorf("parsing Chart.yaml of %s: %w", requirement, err)
+		}
+		if chart.Version != requirement.Version {
+			outdated = append(outdated, requirement)
+		}
+	}
+
+	return outdated, nil
+}
+
+func joinRequirements(requirements helm.Requirements) string {
+	names := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		names = append(names, requirement.String())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// localHelm pulls charts of `file://` repositories from disk and passes all
+// other repositories on to the helm binary. A local repository is a directory
+// with an `index.yaml`, as written by `helm repo index`, or with chart archives
+// named `<name>-<version>.tgz`. Relative paths are resolved against the
+// directory of the chartfile.
+type localHelm struct {
+	helm.Helm
+	dir string
+}
+
+func (h localHelm) RepoUpdate(opts helm.Opts) error {
+	remote := []helm.Repo{}
+	for _, repo := range opts.Repositories {
+		if _, ok := h.localRepository(repo); !ok {
+			remote = append(remote, repo)
+		}
+	}
+
+	// The helm binary is only required for remote repositories
+	if len(remote) == 0 {
+		return nil
+	}
+
+	return h.Helm.RepoUpdate(helm.Opts{Repositories: remote})
+}
+
+func (h localHelm) Pull(chart, version string, opts helm.PullOpts) error {
+	repo_name, name, _ := strings.Cut(chart, "/")
+	for _, repo := range opts.Repositories {
+		if repo.Name != repo_name {
+			continue
+		}
+		if repo_dir, ok := h.localRepository(repo); ok {
+			return pullLocalChart(repo_dir, name, version, opts)
+		}
+	}
+
+	return h.Helm.Pull(chart, version, opts)
+}
+
+func (h localHelm) localRepository(repo helm.Repo) (string, bool) {
+	repo_dir, ok := strings.CutPrefix(repo.URL, "file://")
+	if !ok {
+		return "", false
+	}
+
+	return resolvePath(h.dir, repo_dir), true
+}
+
+// pullLocalChart extracts the chart archive of the given version from a
+// local repository into the destination of the pull options.
+func pullLocalChart(repo_dir, name, version string, opts helm.PullOpts) error {
+	archive, err := localChartArchive(repo_dir, name, version)
+	if err != nil {
+		return err
+	}
+
+	// Extracted next to the destination, so the rename can't cross devices
+	temp_dir, err := os.MkdirTemp(opts.Destination, ".pull-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(temp_dir)
+
+	if err := extractChartArchive(archive, temp_dir); err != nil {
+		return fmt.Errorf("extracting %s: %w", archive, err)
+	}
+
+	extract_dir := opts.ExtractDirectory
+	if extract_dir == "" {
+		extract_dir = name
+	}
+
+	return os.Rename(filepath.Join(temp_dir, name), filepath.Join(opts.Destination, extract_dir))
+}
+
+// localChartArchive finds the archive of the chart version, through the index
+// of the repository when there is one.
+func localChartArchive(repo_dir, name, version string) (string, error) {
+	raw, err := os.ReadFile(filepath.Join(repo_dir, "index.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		archive := filepath.Join(repo_dir, fmt.Sprintf("%s-%s.tgz", name, version))
+		if _, err := os.Stat(archive); err != nil {
+			return "", fmt.Errorf("chart %s@%s not found in local repository %s: %w", name, version, repo_dir, err)
+		}
+		return archive, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	var index struct {
+		Entries map[string][]struct {
+			Version string   `json:"version"`
+			URLs    []string `json:"urls"`
+		} `json:"entries"`
+	}
+	if err := yaml.Unmarshal(raw, &index); err != nil {
+		return "", fmt.Errorf("parsing index.yaml of local repository %s: %w", repo_dir, err)
+	}
+
+	for _, entry := range index.Entries[name] {
+		if entry.Version != version || len(entry.URLs) == 0 {
+			continue
+		}
+
+		url := entry.URLs[0]
+		if file, ok := strings.CutPrefix(url, "file://"); ok {
+			return file, nil
+		}
+		if strings.Contains(url, "://") {
+			return "", fmt.Errorf("chart %s@%s of local repository %s points to the remote %s", name, version, repo_dir, url)
+		}
+		return resolvePath(repo_dir, url), nil
+	}
+
+	return "", fmt.Errorf("chart %s@%s not found in the index of local repository %s", name, version, repo_dir)
+}
+
+// extractChartArchive extracts the regular files and directories of a gzipped
+// tar archive into the directory.
+func extractChartArchive(archive, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			return fmt.Errorf("entry %q leaves the chart directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, reader)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// syncReleaseHelmCharts syncs the charts of the environment when enabled by the
+// helm_charts attribute.
+func syncReleaseHelmCharts(source_path string, mode types.String) (diags diag.Diagnostics) {
+	if mode.IsNull() || mode.IsUnknown() {
+		return
+	}
+
+	if err := syncHelmCharts(source_path, mode.ValueString()); err != nil {
+		diags.AddAttributeError(path.Root("helm_charts"), "Helm Charts Error", fmt.Sprintf("Unable to %s the helm charts of tanka environment, got error: %s", mode.ValueString(), err))
+	}
+
+	return
+}
+
+// planReleaseHelmCharts verifies the charts of the environment during plan,
+// charts are only vendored on apply. With the vendor mode missing charts are
+// reported as a warning, vendored tells whether the environment can be
+// evaluated already.
+func planReleaseHelmCharts(source_path string, mode types.String) (vendored bool, diags diag.Diagnostics) {
+	if mode.IsNull() || mode.IsUnknown() {
+		return true, nil
+	}
+	if mode.ValueString() != helmChartsVendor {
+		diags = syncReleaseHelmCharts(source_path, mode)
+		return !diags.HasError(), diags
+	}
+
+	charts, _, outdated, err := outdatedHelmCharts(source_path)
+	if err != nil {
+		diags.AddAttributeError(path.Root("helm_charts"), "Helm Charts Error", fmt.Sprintf("Unable to verify the helm charts of tanka environment, got error: %s", err))
+		return false, diags
+	}
+	if len(outdated) > 0 {
+		diags.AddAttributeWarning(path.Root("helm_charts"), "Helm Charts Not Vendored", fmt.Sprintf("The charts %s of %s are not vendored yet, they are vendored on apply. The environment is not evaluated during plan until then.", joinRequirements(outdated), charts.ManifestFile()))
+		return false, diags
+	}
+
+	return true, diags
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/helm"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPlanReleaseHelmCharts(t *testing.T) {
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{
+		"jsonnetfile.json":                  `{"version": 1}`,
+		"environments/default/main.jsonnet": `{}`,
+		"chartfile.yaml": `version: 1
+repositories:
+  - name: local
+    url: file://./repo
+requires:
+  - chart: local/app
+    version: 1.0.0
+`,
+	})
+	source_path := filepath.Join(project, "environments", "default")
+
+	t.Run("vendor reports missing charts as a warning", func(t *testing.T) {
+		vendored, diags := planReleaseHelmCharts(source_path, types.StringValue(helmChartsVendor))
+		if vendored {
+			t.Error("expected the charts not to be vendored")
+		}
+		if diags.HasError() || diags.WarningsCount() != 1 {
+			t.Fatalf("expected a single warning, got %v", diags)
+		}
+		if detail := diags[0].Detail(); !strings.Contains(detail, "local/app@1.0.0") {
+			t.Errorf("expected the warning to name the chart, got %s", detail)
+		}
+		if _, err := os.Stat(filepath.Join(project, "charts")); !os.IsNotExist(err) {
+			t.Errorf("expected no charts to be vendored during plan, got %v", err)
+		}
+	})
+
+	t.Run("verify reports missing charts as an error", func(t *testing.T) {
+		vendored, diags := planReleaseHelmCharts(source_path, types.StringValue(helmChartsVerify))
+		if vendored || !diags.HasError() {
+			t.Fatalf("expected an error, got %v", diags)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		vendored, diags := planReleaseHelmCharts(source_path, types.StringNull())
+		if !vendored || len(diags) > 0 {
+			t.Fatalf("expected no check, got %v", diags)
+		}
+	})
+
+	writeTestFiles(t, project, map[string]string{"charts/app/Chart.yaml": "name: app\nversion: 1.0.0\n"})
+	for _, mode := range helmChartsModes {
+		t.Run(mode+" with vendored charts", func(t *testing.T) {
+			vendored, diags := planReleaseHelmCharts(source_path, types.StringValue(mode))
+			if !vendored || len(diags) > 0 {
+				t.Fatalf("expected the charts to be vendored, got %v", diags)
+			}
+		})
+	}
+}
+
+// writeChartArchive writes a gzipped tar archive holding the files.
+func writeChartArchive(t *testing.T, archive string, files map[string]string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(archive), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	writer := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalChartArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		archive string
+		err     string
+	}{
+		{
+			name: "index",
+			files: map[string]string{
+				"index.yaml": "entries:\n  app:\n    - version: 1.1.0\n      urls: [archives/app-1.1.0.tgz]\n    - version: 1.0.0\n      urls: [archives/app-1.0.0.tgz]\n",
+			},
+			archive: filepath.Join("archives", "app-1.0.0.tgz"),
+		},
+		{
+			name: "index with a remote url",
+			files: map[string]string{
+				"index.yaml": "entries:\n  app:\n    - version: 1.0.0\n      urls: [https://charts.example.com/app-1.0.0.tgz]\n",
+			},
+			err: "points to the remote",
+		},
+		{
+			name: "version missing from the index",
+			files: map[string]string{
+				"index.yaml":    "entries:\n  app:\n    - version: 1.1.0\n      urls: [app-1.1.0.tgz]\n",
+				"app-1.0.0.tgz": "",
+			},
+			err: "not found in the index",
+		},
+		{
+			name:    "archive name without index",
+			files:   map[string]string{"app-1.0.0.tgz": ""},
+			archive: "app-1.0.0.tgz",
+		},
+		{
+			name:  "missing archive without index",
+			files: map[string]string{"app-1.1.0.tgz": ""},
+			err:   "not found in local repository",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo_dir := t.TempDir()
+			writeTestFiles(t, repo_dir, test.files)
+
+			archive, err := localChartArchive(repo_dir, "app", "1.0.0")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := filepath.Join(repo_dir, test.archive); archive != expected {
+				t.Errorf("expected %s, got %s", expected, archive)
+			}
+		})
+	}
+}
+
+func TestExtractChartArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("files", func(t *testing.T) {
+		archive := filepath.Join(dir, "app.tgz")
+		writeChartArchive(t, archive, map[string]string{
+			"app/Chart.yaml":            "name: app\nversion: 1.0.0\n",
+			"app/templates/config.yaml": "kind: ConfigMap\n",
+		})
+
+		target := filepath.Join(dir, "files")
+		if err := os.Mkdir(target, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := extractChartArchive(archive, target); err != nil {
+			t.Fatal(err)
+		}
+
+		files := listTestFiles(t, target)
+		expected := []string{filepath.Join("app", "Chart.yaml"), filepath.Join("app", "templates", "config.yaml")}
+		if strings.Join(files, ",") != strings.Join(expected, ",") {
+			t.Errorf("expected %v, got %v", expected, files)
+		}
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		archive := filepath.Join(dir, "evil.tgz")
+		writeChartArchive(t, archive, map[string]string{"../escaped.yaml": "kind: Secret\n"})
+
+		target := filepath.Join(dir, "traversal")
+		if err := os.Mkdir(target, 0755); err != nil {
+			t.Fatal(err)
+		}
+		err := extractChartArchive(archive, target)
+		if err == nil || !strings.Contains(err.Error(), "leaves the chart directory") {
+			t.Fatalf("expected the entry to be rejected, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escaped.yaml")); !os.IsNotExist(err) {
+			t.Errorf("expected no file outside of the chart directory, got %v", err)
+		}
+	})
+}
+
+func TestPullLocalChart(t *testing.T) {
+	repo_dir := t.TempDir()
+	writeChartArchive(t, filepath.Join(repo_dir, "app-1.0.0.tgz"), map[string]string{
+		"app/Chart.yaml": "name: app\nversion: 1.0.0\n",
+	})
+
+	destination := t.TempDir()
+	if err := pullLocalChart(repo_dir, "app", "1.0.0", helm.PullOpts{Destination: destination}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pullLocalChart(repo_dir, "app", "1.0.0", helm.PullOpts{Destination: destination, ExtractDirectory: "app-1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files := listTestFiles(t, destination)
+	expected := []string{filepath.Join("app-1.0.0", "Chart.yaml"), filepath.Join("app", "Chart.yaml")}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v without staging directories, got %v", expected, files)
+	}
+}
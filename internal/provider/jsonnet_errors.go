@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// jsonnetLocation matches locations in Jsonnet error traces, such as
+// `main.jsonnet:12:5-10`, `main.jsonnet:(3:1)-(5:2)` or `<top-level-arg:tf_config>:1:3`.
+var jsonnetLocation = regexp.MustCompile(`(<[^>]+>|[^\s<>()]+):\(?(\d+):(\d+)`)
+
+// tlaAttributes maps the injected TLAs back to the attributes they are set from.
+var tlaAttributes = map[string]string{
+	"<top-level-arg:tf_config>":           "config",
+	"<top-level-arg:tf_config_override>":  "config_override",
+	"<top-level-arg:tf_sensitive_config>": "sensitive_config",
+}
+
+// jsonnetErrorDiagnostic turns an evaluation error into an attribute diagnostic
+// pointing at the first location of the Jsonnet error trace. Errors located in
+// an injected TLA are reported on the matching config attribute, all others on
+// `source_path`.
+func jsonnetErrorDiagnostic(err error, sensitive_config string) diag.Diagnostic {
+	message := redactSensitive(err.Error(), sensitive_config)
+
+	attribute := path.Root("source_path")
+	summary := "Jsonnet Evaluation Error"
+
+	// Runtime errors list the trace below the message, prefer it over
+	// anything resembling a location in the message itself
+	lines := strings.Split(message, "\n")
+	for _, line := range append(lines[1:], lines[0]) {
+		match := jsonnetLocation.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if name, ok := tlaAttributes[match[1]]; ok {
+			attribute = path.Root(name)
+		}
+		summary = fmt.Sprintf("Jsonnet Evaluation Error at %s:%s:%s", match[1], match[2], match[3])
+		break
+	}
+
+	return diag.NewAttributeErrorDiagnostic(
+		attribute,
+		summary,
+		fmt.Sprintf("Unable to evaluate tanka package, got error: %s", message),
+	)
+}
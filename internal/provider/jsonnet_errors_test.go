@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestJsonnetErrorDiagnostic(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.jsonnet")
+	source := "function(tf_config={}, tf_sensitive_config={})\n  if tf_config.replicas > 3 then error 'too many replicas' else tf_config { password: tf_sensitive_config.password }\n"
+	if err := os.WriteFile(main, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	evaluate := func(tlas map[string]string) error {
+		var opts jsonnet.Opts
+		for name, code := range tlas {
+			opts.TLACode.Set(name, code)
+		}
+		_, err := jsonnet.MakeVM(opts).EvaluateFile(main)
+		if err == nil {
+			t.Fatal("expected the evaluation to fail")
+		}
+		return err
+	}
+
+	tests := []struct {
+		name             string
+		err              error
+		sensitive_config string
+		attribute        path.Path
+		summary          string
+	}{
+		{
+			name:      "config tla",
+			err:       evaluate(map[string]string{"tf_config": `{"replicas": }`}),
+			attribute: path.Root("config"),
+			summary:   "Jsonnet Evaluation Error at <top-level-arg:tf_config>:1:",
+		},
+		{
+			name:             "sensitive config tla",
+			err:              evaluate(map[string]string{"tf_config": `{"replicas": 1}`, "tf_sensitive_config": `{"password": "hunter2" + }`}),
+			sensitive_config: `{"password": "hunter2" + }`,
+			attribute:        path.Root("sensitive_config"),
+			summary:          "Jsonnet Evaluation Error at <top-level-arg:tf_sensitive_config>:1:",
+		},
+		{
+			name:      "file",
+			err:       evaluate(map[string]string{"tf_config": `{"replicas": 5}`}),
+			attribute: path.Root("source_path"),
+			summary:   "Jsonnet Evaluation Error at " + main + ":2:",
+		},
+		{
+			name:      "not a jsonnet error",
+			err:       errors.New("unable to connect to the cluster"),
+			attribute: path.Root("source_path"),
+			summary:   "Jsonnet Evaluation Error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostic := jsonnetErrorDiagnostic(test.err, test.sensitive_config)
+
+			with_path, ok := diagnostic.(diag.DiagnosticWithPath)
+			if !ok {
+				t.Fatalf("expected an attribute diagnostic, got %T", diagnostic)
+			}
+			if !with_path.Path().Equal(test.attribute) {
+				t.Errorf("expected the diagnostic on %s, got %s", test.attribute, with_path.Path())
+			}
+			if !strings.HasPrefix(diagnostic.Summary(), test.summary) {
+				t.Errorf("expected summary starting with %q, got %q", test.summary, diagnostic.Summary())
+			}
+			if test.summary == "Jsonnet Evaluation Error" && diagnostic.Summary() != test.summary {
+				t.Errorf("expected no location in the summary, got %q", diagnostic.Summary())
+			}
+			if test.sensitive_config != "" && strings.Contains(diagnostic.Detail(), "hunter2") {
+				t.Errorf("expected the sensitive value to be redacted, got %s", diagnostic.Detail())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/grafana/tanka/pkg/jsonnet/jpath"
+	"github.com/grafana/tanka/pkg/tanka"
+)
+
+// snippetFilename is the name inline snippets are evaluated as, relative
+// imports of a snippet are resolved against the directory it is placed in.
+const snippetFilename = "<snippet>"
+
+// evaluateJsonnet evaluates a file, or the snippet when it is set, with the
+// native functions of tanka. Files inside a tanka project get the import paths
+// of the project, the given import paths are searched first. The expression
+// selects a part of the result the same way `tk eval -e` does, only the
+// selected part is evaluated.
+func evaluateJsonnet(filename, snippet, expression string, opts jsonnet.Opts) (string, error) {
+	if jpaths, _, _, err := jpath.Resolve(filepath.Dir(filename), true); err == nil {
+		// The importer searches the paths in reverse order
+		opts.ImportPaths = append(jpaths, opts.ImportPaths...)
+	}
+
+	vm := jsonnet.MakeVM(opts)
+
+	if expression == "" {
+		if snippet == "" {
+			return vm.EvaluateFile(filename)
+		}
+		return vm.EvaluateAnonymousSnippet(filename, snippet)
+	}
+
+	main := fmt.Sprintf("(%s\n)", snippet)
+	if snippet == "" {
+		quoted, err := json.Marshal(filename)
+		if err != nil {
+			return "", err
+		}
+		main = fmt.Sprintf("(import %s)", quoted)
+	}
+
+	// Top-level arguments are passed through the wrapper to the main file
+	var tlas []string
+	for name := range opts.TLACode {
+		tlas = append(tlas, name)
+	}
+	sort.Strings(tlas)
+
+	script := fmt.Sprintf("local main = %s;\n%s", main, tanka.PatternEvalScript(expression))
+	if len(tlas) > 0 {
+		args := make([]string, 0, len(tlas))
+		for _, name := range tlas {
+			args = append(args, name+"="+name)
+		}
+		// Like jsonnet, the arguments are ignored when main is not a function
+		script = fmt.Sprintf("function(%s)\nlocal __main = %s;\nlocal main = if std.isFunction(__main) then __main(%s) else __main;\n%s", strings.Join(tlas, ", "), main, strings.Join(args, ", "), tanka.PatternEvalScript(expression))
+	}
+
+	return vm.EvaluateAnonymousSnippet(filename, script)
+}
+
+// injectStrings adds the values as string literals to the injected code.
+func injectStrings(code *jsonnet.InjectedCode, values map[string]string) error {
+	for name, value := range values {
+		quoted, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		code.Set(name, string(quoted))
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/jsonnet"
+)
+
+func TestEvaluateJsonnet(t *testing.T) {
+	dir := t.TempDir()
+	object := filepath.Join(dir, "object.jsonnet")
+	if err := os.WriteFile(object, []byte(`{ a: { b: 1 } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	function := filepath.Join(dir, "function.jsonnet")
+	if err := os.WriteFile(function, []byte(`function(x) { a: { b: x } }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tlas jsonnet.InjectedCode
+	tlas.Set("x", "2")
+
+	tests := []struct {
+		name       string
+		filename   string
+		snippet    string
+		expression string
+		opts       jsonnet.Opts
+		expected   string
+	}{
+		{"file", object, "", "", jsonnet.Opts{}, `{"a":{"b":1}}`},
+		{"file with expression", object, "", "a.b", jsonnet.Opts{}, `1`},
+		{"file with unused arguments", object, "", "", jsonnet.Opts{TLACode: tlas}, `{"a":{"b":1}}`},
+		{"expression with unused arguments", object, "", "a.b", jsonnet.Opts{TLACode: tlas}, `1`},
+		{"function", function, "", "", jsonnet.Opts{TLACode: tlas}, `{"a":{"b":2}}`},
+		{"function with expression", function, "", "a.b", jsonnet.Opts{TLACode: tlas}, `2`},
+		{"snippet", filepath.Join(dir, snippetFilename), `{ a: { b: 3 } }`, "a", jsonnet.Opts{TLACode: tlas}, `{"b":3}`},
+		{"function snippet", filepath.Join(dir, snippetFilename), `function(x) { a: x }`, "a", jsonnet.Opts{TLACode: tlas}, `2`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := evaluateJsonnet(test.filename, test.snippet, test.expression, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := normalizeJSON(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/grafana/tanka/pkg/jsonnet"
+	"github.com/grafana/tanka/pkg/tanka"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	lintCheck   = "lint"
+	formatCheck = "format"
+)
+
+// lintSeverities are the accepted values of the lint and format_check
+// attributes of tanka_release.
+var lintSeverities = []string{"warning", "error"}
+
+// defaultLintExcludes skips hidden files and vendored libraries, like `tk lint`
+// and `tk fmt` do.
+var defaultLintExcludes = []string{"**/.*", ".*", "**/vendor/**", "vendor/**"}
+
+// lintLocation matches the first line of a finding printed by the linter,
+// such as `/env/main.jsonnet:1:7-17 Unused variable: x` or
+// `/env/main.jsonnet:(3:1)-(5:2) message`.
+var lintLocation = regexp.MustCompile(`^(.+\.(?:jsonnet|libsonnet)):\(?(\d+):(\d+)\S* (.+)$`)
+
+// lintFinding is a problem found in a Jsonnet file by the linter or the
+// format check.
+type lintFinding struct {
+	Check   string
+	File    string
+	Line    int64
+	Column  int64
+	Message string
+}
+
+func (f lintFinding) location() string {
+	return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+}
+
+// lintJsonnet lints the Jsonnet files below the paths with the linter of
+// go-jsonnet, as `tk lint` does.
+func lintJsonnet(paths []string, excludes []string) (findings []lintFinding, err error) {
+	globs, err := compileExcludes(paths, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	lint_err := jsonnet.Lint(paths, &jsonnet.LintOpts{Excludes: globs, Parallelism: 4, Out: &out})
+
+	// The linter reports files it can't process the same way as findings
+	findings, unparsed := parseLintOutput(out.String())
+	if len(unparsed) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(unparsed, "\n"))
+	}
+	if lint_err != nil && len(findings) == 0 {
+		return nil, lint_err
+	}
+
+	sortLintFindings(findings)
+	return findings, nil
+}
+
+// parseLintOutput reads the findings from the output of the linter. Files the
+// linter failed to process are returned as unparsed lines.
+func parseLintOutput(output string) (findings []lintFinding, unparsed []string) {
+	for _, line := range strings.Split(output, "\n") {
+		match := lintLocation.FindStringSubmatch(line)
+		if match == nil {
+			if strings.HasPrefix(line, "got an error") || strings.HasPrefix(line, "caught a panic") {
+				unparsed = append(unparsed, line)
+			}
+			continue
+		}
+
+		line_number, _ := strconv.ParseInt(match[2], 10, 64)
+		column, _ := strconv.ParseInt(match[3], 10, 64)
+		findings = append(findings, lintFinding{Check: lintCheck, File: match[1], Line: line_number, Column: column, Message: match[4]})
+	}
+
+	return
+}
+
+// checkFormat reports the Jsonnet files below the paths that `tk fmt` would
+// change, located at the first line that differs. No file is written.
+func checkFormat(paths []string, excludes []string) (findings []lintFinding, err error) {
+	globs, err := compileExcludes(paths, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tanka.FormatFiles(paths, &tanka.FormatOpts{
+		Excludes: globs,
+		OutFn: func(name, formatted string) error {
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			if line_number := firstDifferentLine(string(content), formatted); line_number > 0 {
+				findings = append(findings, lintFinding{Check: formatCheck, File: name, Line: line_number, Column: 1, Message: "File is not formatted, run `tk fmt` to format it"})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortLintFindings(findings)
+	return findings, nil
+}
+
+// firstDifferentLine returns the 1-based number of the first line that differs
+// between both texts, or 0 when they are equal.
+func firstDifferentLine(a, b string) int64 {
+	if a == b {
+		return 0
+	}
+
+	a_lines, b_lines := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := 0; i < len(a_lines) && i < len(b_lines); i++ {
+		if a_lines[i] != b_lines[i] {
+			return int64(i + 1)
+		}
+	}
+
+	return int64(min(len(a_lines), len(b_lines)) + 1)
+}
+
+// relativeGlob matches the files found below the roots by their path relative
+// to the root. Tanka matches the full path, which would exclude everything
+// below a hidden directory such as `.terraform/modules`.
+type relativeGlob struct {
+	roots []string
+	glob  glob.Glob
+}
+
+func (g relativeGlob) Match(file string) bool {
+	for _, root := range g.roots {
+		if relative, ok := strings.CutPrefix(file, root+"/"); ok {
+			return g.glob.Match(relative)
+		}
+	}
+
+	return g.glob.Match(file)
+}
+
+func compileExcludes(roots []string, excludes []string) ([]glob.Glob, error) {
+	slash_roots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		slash_roots = append(slash_roots, strings.TrimSuffix(filepath.ToSlash(root), "/"))
+	}
+
+	globs := make([]glob.Glob, 0, len(excludes))
+	for _, exclude := range excludes {
+		g, err := glob.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", exclude, err)
+		}
+		globs = append(globs, relativeGlob{roots: slash_roots, glob: g})
+	}
+
+	return globs, nil
+}
+
+func sortLintFindings(findings []lintFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+}
+
+// lintDiagnostics reports the findings on the attribute that enabled the
+// check, as warnings or errors depending on the severity.
+func lintDiagnostics(attribute path.Path, severity string, findings []lintFinding) (diags diag.Diagnostics) {
+	for _, finding := range findings {
+		summary := fmt.Sprintf("Jsonnet Lint Finding at %s", finding.location())
+		if finding.Check == formatCheck {
+			summary = fmt.Sprintf("Jsonnet Format Finding at %s", finding.location())
+		}
+
+		if severity == "error" {
+			diags.AddAttributeError(attribute, summary, finding.Message)
+		} else {
+			diags.AddAttributeWarning(attribute, summary, finding.Message)
+		}
+	}
+
+	return
+}
+
+// checkEnvironmentSources runs the enabled checks on the directory of the
+// environment at the source path.
+func checkEnvironmentSources(source_path string, lint, format_check types.String) (diags diag.Diagnostics) {
+	dir := source_path
+	if info, err := os.Stat(source_path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(source_path)
+	}
+
+	if !lint.IsNull() && !lint.IsUnknown() {
+		findings, err := lintJsonnet([]string{dir}, defaultLintExcludes)
+		if err != nil {
+			diags.AddAttributeError(path.Root("lint"), "Lint Error", fmt.Sprintf("Unable to lint tanka environment, got error: %s", err))
+			return
+		}
+		diags.Append(lintDiagnostics(path.Root("lint"), lint.ValueString(), findings)...)
+	}
+
+	if !format_check.IsNull() && !format_check.IsUnknown() {
+		findings, err := checkFormat([]string{dir}, defaultLintExcludes)
+		if err != nil {
+			diags.AddAttributeError(path.Root("format_check"), "Format Error", fmt.Sprintf("Unable to check the format of tanka environment, got error: %s", err))
+			return
+		}
+		diags.Append(lintDiagnostics(path.Root("format_check"), format_check.ValueString(), findings)...)
+	}
+
+	return
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLintOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		findings []lintFinding
+		unparsed []string
+	}{
+		{
+			name:   "findings",
+			output: "/env/main.jsonnet:1:7-12 Unused variable: x\n\nlocal x = 1;\n\n\n/env/main.jsonnet:3:6-13 Indexed object has no field \"foo\"\n\n  a: std.foo(1),\n\n\n",
+			findings: []lintFinding{
+				{Check: lintCheck, File: "/env/main.jsonnet", Line: 1, Column: 7, Message: "Unused variable: x"},
+				{Check: lintCheck, File: "/env/main.jsonnet", Line: 3, Column: 6, Message: `Indexed object has no field "foo"`},
+			},
+		},
+		{
+			name:   "multi-line location",
+			output: "/lib/util.libsonnet:(3:1)-(5:2) Unused variable: helpers\n\nlocal helpers = {\n",
+			findings: []lintFinding{
+				{Check: lintCheck, File: "/lib/util.libsonnet", Line: 3, Column: 1, Message: "Unused variable: helpers"},
+			},
+		},
+		{
+			name:   "source lines",
+			output: "/env/main.jsonnet:2:3-8 Unknown variable: confg\n\n  a: 'http://example.com:8080 b',\n",
+			findings: []lintFinding{
+				{Check: lintCheck, File: "/env/main.jsonnet", Line: 2, Column: 3, Message: "Unknown variable: confg"},
+			},
+		},
+		{
+			name:     "unprocessed file",
+			output:   "got an error when linting /env/broken.jsonnet: STATIC ERROR\n",
+			unparsed: []string{"got an error when linting /env/broken.jsonnet: STATIC ERROR"},
+		},
+		{
+			name:   "nothing found",
+			output: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings, unparsed := parseLintOutput(test.output)
+			if !reflect.DeepEqual(findings, test.findings) {
+				t.Errorf("expected findings %+v, got %+v", test.findings, findings)
+			}
+			if !reflect.DeepEqual(unparsed, test.unparsed) {
+				t.Errorf("expected unparsed lines %q, got %q", test.unparsed, unparsed)
+			}
+		})
+	}
+}
+
+func TestFirstDifferentLine(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		line int64
+	}{
+		{name: "equal", a: "{\n  a: 1,\n}\n", b: "{\n  a: 1,\n}\n", line: 0},
+		{name: "first line", a: "{a: 1}\n", b: "{ a: 1 }\n", line: 1},
+		{name: "added blank line", a: "{ a: 1 }\n", b: "{ a: 1 }\n\n", line: 3},
+		{name: "changed line", a: "{\n  a:1,\n}\n", b: "{\n  a: 1,\n}\n", line: 2},
+		{name: "missing trailing newline", a: "{}", b: "{}\n", line: 2},
+		{name: "longer original", a: "{}\n\n\n", b: "{}\n", line: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if line := firstDifferentLine(test.a, test.b); line != test.line {
+				t.Errorf("expected line %d, got %d", test.line, line)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// manifestOutputs converts rendered manifests into the `manifests` and `yaml`
+// attributes shared by the data sources rendering manifests. The manifests
+// are a list of objects, typed the same way `jsondecode()` would type them.
+func manifestOutputs(manifests manifest.List) (objects types.Dynamic, yaml types.String, diags diag.Diagnostics) {
+	raw, err := json.Marshal(manifests)
+	if err != nil {
+		diags.AddError("Marshal Error", fmt.Sprintf("Unable to encode manifests, got error: %s", err))
+		return
+	}
+
+	decoded, err := decodeJSON(string(raw))
+	if err != nil {
+		diags.AddError("Parse Error", fmt.Sprintf("Unable to parse manifests, got error: %s", err))
+		return
+	}
+
+	// An empty list is encoded as null
+	if decoded == nil {
+		decoded = []interface{}{}
+	}
+
+	value, err := interfaceToAttr(decoded)
+	if err != nil {
+		diags.AddError("Parse Error", fmt.Sprintf("Unable to convert manifests, got error: %s", err))
+		return
+	}
+
+	return types.DynamicValue(value), types.StringValue(manifests.String()), diags
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestManifestOutputs(t *testing.T) {
+	manifests := manifest.List{
+		{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "web"}},
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web"}, "spec": map[string]interface{}{"replicas": float64(2)}},
+	}
+
+	objects, yaml, diags := manifestOutputs(manifests)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	tuple, ok := objects.UnderlyingValue().(types.Tuple)
+	if !ok || len(tuple.Elements()) != 2 {
+		t.Fatalf("expected a tuple of two objects, got %s", objects)
+	}
+	deployment, ok := tuple.Elements()[1].(types.Object)
+	if !ok {
+		t.Fatalf("expected an object, got %s", tuple.Elements()[1])
+	}
+	spec, ok := deployment.Attributes()["spec"].(types.Object)
+	if !ok {
+		t.Fatalf("expected the spec to be an object, got %s", deployment.Attributes()["spec"])
+	}
+	if replicas := spec.Attributes()["replicas"]; !replicas.Equal(types.NumberValue(big.NewFloat(2))) {
+		t.Errorf("expected 2 replicas, got %s", replicas)
+	}
+
+	if yaml.ValueString() != manifests.String() {
+		t.Errorf("expected the yaml stream of the manifests, got %s", yaml)
+	}
+
+	objects, yaml, diags = manifestOutputs(manifest.List{})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if tuple, ok := objects.UnderlyingValue().(types.Tuple); !ok || len(tuple.Elements()) != 0 {
+		t.Errorf("expected an empty tuple, got %s", objects)
+	}
+	if yaml.ValueString() != "" {
+		t.Errorf("expected an empty yaml stream, got %q", yaml.ValueString())
+	}
+}
@@ -0,0 +1,31 @@
+package provider
+
+// mergePatch applies patch to target following RFC 7386, the same semantics
+// as `std.mergePatch` in Jsonnet: objects are merged recursively, null values
+// in the patch delete keys and any other value replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patch_object, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	target_object, ok := target.(map[string]interface{})
+	if !ok {
+		target_object = map[string]interface{}{}
+	}
+
+	result := make(map[string]interface{}, len(target_object))
+	for key, value := range target_object {
+		result[key] = value
+	}
+
+	for key, value := range patch_object {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatch(result[key], value)
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7386, Appendix A
+	tests := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.target+" "+test.patch, func(t *testing.T) {
+			target, err := decodeJSON(test.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patch, err := decodeJSON(test.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := decodeJSON(test.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := mergePatch(target, patch)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %#v, got %#v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestMergePatchKeepsTarget(t *testing.T) {
+	target := map[string]interface{}{"a": map[string]interface{}{"b": "c"}}
+	mergePatch(target, map[string]interface{}{"a": nil})
+
+	if _, ok := target["a"]; !ok {
+		t.Error("expected the target to be left unmodified")
+	}
+}
@@ -31,6 +31,8 @@ type TankaProviderModel struct {
 	Endpoint             types.String `tfsdk:"endpoint"`
 	ClusterCaCertificate types.String `tfsdk:"cluster_ca_certificate"`
 	Token                types.String `tfsdk:"token"`
+	BaseDir              types.String `tfsdk:"base_dir"`
+	SopsAgeKeyFile       types.String `tfsdk:"sops_age_key_file"`
 }
 
 func (p *TankaProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -53,6 +55,14 @@ func (p *TankaProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				MarkdownDescription: "Token for the user entry in kubeconfig",
 				Required:            true,
 			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative `source_path` and `file://` paths of all releases are resolved against, unless overridden by the release. Defaults to the working directory of Terraform",
+				Optional:            true,
+			},
+			"sops_age_key_file": schema.StringAttribute{
+				MarkdownDescription: "Age key file used to decrypt SOPS encrypted sources of `sensitive_config`. Defaults to `SOPS_AGE_KEY_FILE` and then to the default location of SOPS, keys in `SOPS_AGE_KEY` are used as well",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -69,8 +79,10 @@ func (p *TankaProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	endpoint := data.Endpoint.ValueString()
 	token := data.Token.ValueString()
 	cluster_ca_certificate := data.ClusterCaCertificate.ValueString()
+	base_dir := data.BaseDir.ValueString()
+	sops_age_key_file := data.SopsAgeKeyFile.ValueString()
 
-	client, err := NewClient(&endpoint, &token, &cluster_ca_certificate)
+	client, err := NewClient(&endpoint, &token, &cluster_ca_certificate, &base_dir, &sops_age_key_file)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Create Tanka API Client",
@@ -88,15 +100,31 @@ func (p *TankaProvider) Configure(ctx context.Context, req provider.ConfigureReq
 func (p *TankaProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewTankaReleaseResource,
+		NewTankaExportResource,
 	}
 }
 
 func (p *TankaProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewTankaManifestsDataSource,
+		NewTankaEnvironmentsDataSource,
+		NewTankaEvalDataSource,
+		NewTankaDiffDataSource,
+		NewTankaReleaseStatusDataSource,
+		NewTankaLintDataSource,
+		NewTankaHelmTemplateDataSource,
+		NewTankaKustomizeBuildDataSource,
+	}
 }
 
 func (p *TankaProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewMergePatchFunction,
+		NewJsonnetEvaluateFunction,
+		NewJsonnetEvaluateFileFunction,
+		NewManifestYamlFunction,
+		NewParseYamlStreamFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
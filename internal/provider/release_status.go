@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+)
+
+// objectStatus is the health of a live object.
+type objectStatus struct {
+	APIVersion         string
+	Kind               string
+	Namespace          string
+	Name               string
+	Ready              bool
+	Generation         *int64
+	ObservedGeneration *int64
+	Replicas           *int64
+	ReadyReplicas      *int64
+	Images             []string
+	Conditions         []objectCondition
+}
+
+// objectCondition is an entry of `status.conditions`.
+type objectCondition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// newObjectStatus reads the health of a live object from its status. Objects
+// without a known notion of readiness are ready once they exist.
+func newObjectStatus(m manifest.Manifest) objectStatus {
+	status := objectStatus{
+		APIVersion:         m.APIVersion(),
+		Kind:               m.Kind(),
+		Namespace:          m.Metadata().Namespace(),
+		Name:               m.Metadata().Name(),
+		Generation:         nestedInt64(m, "metadata", "generation"),
+		ObservedGeneration: nestedInt64(m, "status", "observedGeneration"),
+		Images:             objectImages(m),
+	}
+
+	if conditions, ok := nestedValue(m, "status", "conditions").([]interface{}); ok {
+		for _, item := range conditions {
+			condition, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			status.Conditions = append(status.Conditions, objectCondition{
+				Type:    fmt.Sprint(condition["type"]),
+				Status:  fmt.Sprint(condition["status"]),
+				Reason:  nestedString(condition, "reason"),
+				Message: nestedString(condition, "message"),
+			})
+		}
+	}
+
+	switch status.Kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController":
+		status.Replicas = nestedInt64(m, "spec", "replicas")
+		if status.Replicas == nil {
+			one := int64(1)
+			status.Replicas = &one
+		}
+		status.ReadyReplicas = int64OrZero(nestedInt64(m, "status", "readyReplicas"))
+	case "DaemonSet":
+		status.Replicas = int64OrZero(nestedInt64(m, "status", "desiredNumberScheduled"))
+		status.ReadyReplicas = int64OrZero(nestedInt64(m, "status", "numberReady"))
+	}
+
+	status.Ready = status.ready(m)
+
+	return status
+}
+
+// ready decides whether the object is ready, following the checks of
+// `kubectl rollout status` for workloads.
+func (s objectStatus) ready(m manifest.Manifest) bool {
+	// The controller hasn't seen the latest change yet
+	if s.Generation != nil && s.ObservedGeneration != nil && *s.ObservedGeneration < *s.Generation {
+		return false
+	}
+
+	switch s.Kind {
+	case "Deployment":
+		updated := int64OrZero(nestedInt64(m, "status", "updatedReplicas"))
+		available := int64OrZero(nestedInt64(m, "status", "availableReplicas"))
+		return *updated >= *s.Replicas && *available >= *s.Replicas && *s.ReadyReplicas >= *s.Replicas
+	case "StatefulSet":
+		updated := int64OrZero(nestedInt64(m, "status", "updatedReplicas"))
+		return *updated >= *s.Replicas && *s.ReadyReplicas >= *s.Replicas
+	case "ReplicaSet", "ReplicationController":
+		return *s.ReadyReplicas >= *s.Replicas
+	case "DaemonSet":
+		updated := int64OrZero(nestedInt64(m, "status", "updatedNumberScheduled"))
+		return *updated >= *s.Replicas && *s.ReadyReplicas >= *s.Replicas
+	case "Job":
+		return s.condition("Complete") == "True"
+	case "PersistentVolumeClaim":
+		return nestedString(m, "status", "phase") == "Bound"
+	}
+
+	for _, condition := range []string{"Ready", "Available"} {
+		if value := s.condition(condition); value != "" {
+			return value == "True"
+		}
+	}
+
+	return true
+}
+
+// condition returns the status of the condition of the given type, or the
+// empty string when the object doesn't report it.
+func (s objectStatus) condition(condition_type string) string {
+	for _, condition := range s.Conditions {
+		if condition.Type == condition_type {
+			return condition.Status
+		}
+	}
+
+	return ""
+}
+
+// releaseHealthy reports whether all objects are ready. An environment
+// without any live objects is not rolled out yet.
+func releaseHealthy(statuses []objectStatus) bool {
+	for _, status := range statuses {
+		if !status.Ready {
+			return false
+		}
+	}
+
+	return len(statuses) > 0
+}
+
+// objectImages returns the sorted images of the containers of workloads and
+// pods.
+func objectImages(m manifest.Manifest) []string {
+	pod_spec, _ := nestedValue(m, "spec", "template", "spec").(map[string]interface{})
+	switch m.Kind() {
+	case "Pod":
+		pod_spec, _ = nestedValue(m, "spec").(map[string]interface{})
+	case "CronJob":
+		pod_spec, _ = nestedValue(m, "spec", "jobTemplate", "spec", "template", "spec").(map[string]interface{})
+	}
+
+	seen := map[string]bool{}
+	images := []string{}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := pod_spec[field].([]interface{})
+		for _, item := range containers {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image := nestedString(container, "image"); image != "" && !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	sort.Strings(images)
+
+	return images
+}
+
+func nestedValue(object map[string]interface{}, fields ...string) interface{} {
+	var value interface{} = object
+	for _, field := range fields {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = current[field]
+	}
+
+	return value
+}
+
+func nestedString(object map[string]interface{}, fields ...string) string {
+	value, _ := nestedValue(object, fields...).(string)
+	return value
+}
+
+// nestedInt64 returns the number at the given fields, or nil when it is not
+// set. Objects fetched with kubectl are decoded with float64 numbers.
+func nestedInt64(object map[string]interface{}, fields ...string) *int64 {
+	var number int64
+	switch value := nestedValue(object, fields...).(type) {
+	case float64:
+		number = int64(value)
+	case int64:
+		number = value
+	case int:
+		number = int64(value)
+	default:
+		return nil
+	}
+
+	return &number
+}
+
+func int64OrZero(value *int64) *int64 {
+	if value == nil {
+		zero := int64(0)
+		return &zero
+	}
+
+	return value
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/tanka/pkg/kubernetes/manifest"
+)
+
+func TestNewObjectStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		object string
+		ready  bool
+	}{
+		{
+			name:   "deployment rolled out",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 2, "updatedReplicas": 2, "readyReplicas": 2, "availableReplicas": 2}}`,
+			ready:  true,
+		},
+		{
+			name:   "deployment rolling out",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 1, "readyReplicas": 2, "availableReplicas": 2}}`,
+			ready:  false,
+		},
+		{
+			name:   "deployment not observed",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "generation": 3}, "spec": {"replicas": 1}, "status": {"observedGeneration": 2, "updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}}`,
+			ready:  false,
+		},
+		{
+			name:   "deployment with default replicas",
+			object: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "status": {"updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}}`,
+			ready:  true,
+		},
+		{
+			name:   "statefulset ready",
+			object: `{"apiVersion": "apps/v1", "kind": "StatefulSet", "metadata": {"name": "db"}, "spec": {"replicas": 3}, "status": {"updatedReplicas": 3, "readyReplicas": 3}}`,
+			ready:  true,
+		},
+		{
+			name:   "statefulset not updated",
+			object: `{"apiVersion": "apps/v1", "kind": "StatefulSet", "metadata": {"name": "db"}, "spec": {"replicas": 3}, "status": {"updatedReplicas": 2, "readyReplicas": 3}}`,
+			ready:  false,
+		},
+		{
+			name:   "daemonset ready",
+			object: `{"apiVersion": "apps/v1", "kind": "DaemonSet", "metadata": {"name": "agent"}, "status": {"desiredNumberScheduled": 4, "updatedNumberScheduled": 4, "numberReady": 4}}`,
+			ready:  true,
+		},
+		{
+			name:   "daemonset not ready",
+			object: `{"apiVersion": "apps/v1", "kind": "DaemonSet", "metadata": {"name": "agent"}, "status": {"desiredNumberScheduled": 4, "updatedNumberScheduled": 4, "numberReady": 3}}`,
+			ready:  false,
+		},
+		{
+			name:   "job complete",
+			object: `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "migrate"}, "status": {"conditions": [{"type": "Complete", "status": "True"}]}}`,
+			ready:  true,
+		},
+		{
+			name:   "job running",
+			object: `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "migrate"}, "status": {"active": 1}}`,
+			ready:  false,
+		},
+		{
+			name:   "ready condition",
+			object: `{"apiVersion": "cert-manager.io/v1", "kind": "Certificate", "metadata": {"name": "tls"}, "status": {"conditions": [{"type": "Ready", "status": "False", "reason": "Pending"}]}}`,
+			ready:  false,
+		},
+		{
+			name:   "available condition",
+			object: `{"apiVersion": "apiregistration.k8s.io/v1", "kind": "APIService", "metadata": {"name": "v1beta1.metrics.k8s.io"}, "status": {"conditions": [{"type": "Available", "status": "True"}]}}`,
+			ready:  true,
+		},
+		{
+			name:   "without readiness",
+			object: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}, "data": {}}`,
+			ready:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var object manifest.Manifest
+			if err := json.Unmarshal([]byte(test.object), &object); err != nil {
+				t.Fatal(err)
+			}
+
+			status := newObjectStatus(object)
+			if status.Ready != test.ready {
+				t.Errorf("expected ready %t, got %t", test.ready, status.Ready)
+			}
+			if healthy := releaseHealthy([]objectStatus{status}); healthy != test.ready {
+				t.Errorf("expected healthy %t, got %t", test.ready, healthy)
+			}
+		})
+	}
+}
+
+func TestReleaseHealthy(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []objectStatus
+		healthy  bool
+	}{
+		{name: "no objects", statuses: nil, healthy: false},
+		{name: "all ready", statuses: []objectStatus{{Ready: true}, {Ready: true}}, healthy: true},
+		{name: "one not ready", statuses: []objectStatus{{Ready: true}, {Ready: false}}, healthy: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if healthy := releaseHealthy(test.statuses); healthy != test.healthy {
+				t.Errorf("expected healthy %t, got %t", test.healthy, healthy)
+			}
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/tanka/pkg/tanka"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &TankaExportResource{}
+var _ resource.ResourceWithModifyPlan = &TankaExportResource{}
+var _ resource.ResourceWithValidateConfig = &TankaExportResource{}
+
+const (
+	defaultExportFormat      = "{{.apiVersion}}.{{.kind}}-{{or .metadata.name .metadata.generateName}}"
+	defaultExportExtension   = "yaml"
+	defaultExportParallelism = 8
+)
+
+// exportMergeStrategies are the merge strategies tanka accepts, besides the
+// default of exporting to an empty directory only.
+var exportMergeStrategies = []string{string(tanka.ExportMergeStrategyFailConflicts), string(tanka.ExportMergeStrategyReplaceEnvs)}
+
+func NewTankaExportResource() resource.Resource {
+	return &TankaExportResource{}
+}
+
+// TankaExportResource defines the resource implementation.
+type TankaExportResource struct {
+	client *Client
+}
+
+// TankaExportResourceModel describes the resource data model.
+type TankaExportResourceModel struct {
+	Id             types.String `tfsdk:"id"`
+	SourcePath     types.String `tfsdk:"source_path"`
+	OutputDir      types.String `tfsdk:"output_dir"`
+	BaseDir        types.String `tfsdk:"base_dir"`
+	Namespace      types.String `tfsdk:"namespace"`
+	Config         ConfigString `tfsdk:"config"`
+	ConfigOverride ConfigString `tfsdk:"config_override"`
+	Selector       types.String `tfsdk:"selector"`
+	Format         types.String `tfsdk:"format"`
+	Extension      types.String `tfsdk:"extension"`
+	MergeStrategy  types.String `tfsdk:"merge_strategy"`
+	Parallelism    types.Int64  `tfsdk:"parallelism"`
+	Files          types.Map    `tfsdk:"files"`
+}
+
+func (r *TankaExportResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "tanka_export"
+}
+
+func (r *TankaExportResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Exports the manifests of Tanka environments as YAML files to a directory, like `tk export`, instead of applying them.",
+
+		Attributes: map[string]schema.Attribute{
+			"source_path": schema.StringAttribute{
+				MarkdownDescription: "Environment to export, or directory that is searched recursively for environments. Relative paths are resolved against the base directory. Defaults to `tanka/environments`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultEnvironmentsPath),
+			},
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory the files are written to. Relative paths are resolved against the base directory. Changing it exports to the new directory and removes the files from the old one.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative `source_path`, `output_dir` and `file://` paths are resolved against. Overrides the `base_dir` of the provider. Changing it, or the `base_dir` of the provider when `output_dir` is relative, exports to the new directory and removes the files from the old one.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "The Kubernetes namespace passed to inline environments. Defaults to `default`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultNamespace),
+			},
+			"config": schema.StringAttribute{
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Configuration object passed to inline environments as `tf_config`, accepting the same sources as `config` of `tanka_release`. Defaults to the empty object.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("{}"),
+			},
+			"config_override": schema.StringAttribute{
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Configuration override object passed to inline environments as `tf_config_override`, accepting the same sources as `config` of `tanka_release`. Defaults to the empty object.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("{}"),
+			},
+			"selector": schema.StringAttribute{
+				MarkdownDescription: "Kubernetes label selector the labels of the exported environments have to match, e.g. `team=infra,tier!=dev`.",
+				Optional:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "Go template of the file names, without extension. The manifest is passed as `.` and the environment is available as `env`, a `/` creates a directory. Defaults to `" + defaultExportFormat + "`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultExportFormat),
+			},
+			"extension": schema.StringAttribute{
+				MarkdownDescription: "Extension of the file names. Defaults to `" + defaultExportExtension + "`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultExportExtension),
+			},
+			"merge_strategy": schema.StringAttribute{
+				MarkdownDescription: "How to export to a directory that isn't empty. With `fail-on-conflicts` the export fails when a file already exists, with `replace-envs` the files previously exported by the same environments are replaced. When not set, the directory has to be empty apart from the files of this resource.",
+				Optional:            true,
+			},
+			"parallelism": schema.Int64Attribute{
+				MarkdownDescription: "Number of environments evaluated in parallel. Defaults to `8`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(defaultExportParallelism),
+			},
+			"files": schema.MapAttribute{
+				MarkdownDescription: "SHA-256 hash of each written file, keyed by the path relative to `output_dir`. The manifests are rendered during plan as well, so changes of the rendered manifests and of the files on disk show up as an update.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The absolute path of the output directory.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *TankaExportResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *TankaExportResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data TankaExportResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.export(ctx, &data, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "created a resource")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TankaExportResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data TankaExportResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var files map[string]string
+	resp.Diagnostics.Append(data.Files.ElementsAs(ctx, &files, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Files changed or removed outside of Terraform are exported again by the
+	// next apply
+	current := map[string]string{}
+	for file := range files {
+		hash, err := hashExportedFile(resolvePath(data.Id.ValueString(), file))
+		if err != nil {
+			continue
+		}
+		current[file] = hash
+	}
+
+	var diags diag.Diagnostics
+	data.Files, diags = types.MapValueFrom(ctx, types.StringType, current)
+	resp.Diagnostics.Append(diags...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TankaExportResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state TankaExportResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The files of the previous export are replaced, the ones that aren't
+	// exported again are removed once the export succeeded
+	var previous map[string]string
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.export(ctx, &data, exportedFileNames(previous))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "updated a resource")
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TankaExportResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data TankaExportResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(removeExport(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The output directory is only removed when nothing else is left in it
+	if entries, err := os.ReadDir(data.Id.ValueString()); err == nil && len(entries) == 0 {
+		if err := os.Remove(data.Id.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Delete Error", fmt.Sprintf("Unable to remove the output directory %s, got error: %s", data.Id.ValueString(), err))
+			return
+		}
+	}
+
+	// Write logs using the tflog package
+	// Documentation: https://terraform.io/plugin/log
+	tflog.Trace(ctx, "deleted a resource")
+}
+
+func (r *TankaExportResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data TankaExportResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Selector.IsNull() && !data.Selector.IsUnknown() {
+		if _, err := labels.Parse(data.Selector.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("selector"), "Invalid Selector", fmt.Sprintf("Unable to parse label selector, got error: %s", err))
+		}
+	}
+
+	if !data.MergeStrategy.IsNull() && !data.MergeStrategy.IsUnknown() {
+		known := false
+		for _, strategy := range exportMergeStrategies {
+			known = known || data.MergeStrategy.ValueString() == strategy
+		}
+		if !known {
+			resp.Diagnostics.AddAttributeError(path.Root("merge_strategy"), "Invalid Merge Strategy", fmt.Sprintf("Unknown merge strategy %q, expected one of %s.", data.MergeStrategy.ValueString(), strings.Join(exportMergeStrategies, ", ")))
+		}
+	}
+
+	if !data.Parallelism.IsNull() && !data.Parallelism.IsUnknown() && data.Parallelism.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("parallelism"), "Invalid Parallelism", "At least one environment has to be evaluated at a time.")
+	}
+}
+
+func (r *TankaExportResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// The provider is not configured yet when only validating
+	if r.client == nil {
+		return
+	}
+
+	var data TankaExportResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SourcePath.IsUnknown() || data.OutputDir.IsUnknown() || data.BaseDir.IsUnknown() || data.Namespace.IsUnknown() ||
+		data.Config.IsUnknown() || data.ConfigOverride.IsUnknown() || data.Selector.IsUnknown() || data.Format.IsUnknown() ||
+		data.Extension.IsUnknown() || data.Parallelism.IsUnknown() {
+		tflog.Debug(ctx, "skipping plan-time export of the tanka environments, not all values are known")
+		return
+	}
+
+	opts, diags := r.client.parseOpts(ctx, data.BaseDir, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The output directory also moves with the base directory of the provider,
+	// the files of the old one are removed by replacing the export
+	output_dir := resolvePath(opts.BaseDir, data.OutputDir.ValueString())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), output_dir)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var id types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if id.ValueString() != output_dir {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("id"))
+		}
+	}
+
+	// The manifests are rendered without writing them, the hashes are the
+	// ones of the files the export writes
+	rendered, _, diags := r.renderFiles(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, diags := exportedHashes(ctx, rendered)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("files"), files)...)
+}
+
+// export writes the files to the output directory and records them. The files
+// have to match the ones of the plan, nothing is written otherwise. The files
+// of the previous export are replaced.
+func (r *TankaExportResource) export(ctx context.Context, data *TankaExportResourceModel, previous []string) (diags diag.Diagnostics) {
+	opts, d := r.client.parseOpts(ctx, data.BaseDir, nil)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	output_dir := resolvePath(opts.BaseDir, data.OutputDir.ValueString())
+
+	rendered, envs, d := r.renderFiles(ctx, data)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	files, d := exportedHashes(ctx, rendered)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	if !data.Files.IsUnknown() && !data.Files.Equal(files) {
+		diags.AddError("Export Changed", "The exported files differ from the ones of the plan, the environments or config sources changed after the plan was made. Create a new plan to export the current content.")
+		return
+	}
+
+	if err := writeExport(output_dir, rendered, envs, tanka.ExportMergeStrategy(data.MergeStrategy.ValueString()), previous); err != nil {
+		diags.AddError("Export Error", fmt.Sprintf("Unable to write the exported files to %s, got error: %s", output_dir, err))
+		return
+	}
+
+	data.Id = types.StringValue(output_dir)
+	data.Files = files
+
+	return
+}
+
+// renderFiles renders the manifests of the environments to the files of the
+// export, without writing them.
+func (r *TankaExportResource) renderFiles(ctx context.Context, data *TankaExportResourceModel) (files map[string]exportedFile, envs map[string]bool, diags diag.Diagnostics) {
+	opts, d := r.client.parseOpts(ctx, data.BaseDir, nil)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	resolved, d := r.client.resolveInputs(ctx, configInputs{Config: data.Config, ConfigOverride: data.ConfigOverride}, opts)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	selector, err := labels.Parse(data.Selector.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("selector"), "Invalid Selector", fmt.Sprintf("Unable to parse label selector, got error: %s", err))
+		return
+	}
+
+	export_opts := tanka.ExportEnvOpts{
+		Format:      data.Format.ValueString(),
+		Extension:   data.Extension.ValueString(),
+		Selector:    selector,
+		Parallelism: int(data.Parallelism.ValueInt64()),
+	}
+
+	source_path := resolvePath(opts.BaseDir, data.SourcePath.ValueString())
+	files, envs, err = r.client.RenderExport(r.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, source_path, export_opts)
+	if err != nil {
+		diags.AddError("Export Error", fmt.Sprintf("Unable to export tanka environments, got error: %s", err))
+		return
+	}
+
+	return
+}
+
+// exportedHashes returns the SHA-256 hash of each rendered file.
+func exportedHashes(ctx context.Context, rendered map[string]exportedFile) (types.Map, diag.Diagnostics) {
+	hashes := make(map[string]string, len(rendered))
+	for file, exported := range rendered {
+		hashes[file] = hashExportContent(exported.Content)
+	}
+
+	return types.MapValueFrom(ctx, types.StringType, hashes)
+}
+
+// removeExport removes the files recorded in state from the output directory.
+func removeExport(ctx context.Context, data *TankaExportResourceModel) (diags diag.Diagnostics) {
+	var files map[string]string
+	diags.Append(data.Files.ElementsAs(ctx, &files, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	if err := removeExportedFiles(data.Id.ValueString(), exportedFileNames(files)); err != nil {
+		diags.AddError("Delete Error", fmt.Sprintf("Unable to remove the exported files from %s, got error: %s", data.Id.ValueString(), err))
+	}
+
+	return
+}
@@ -6,8 +6,12 @@ package provider
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -15,6 +19,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -22,6 +27,8 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &TankaReleaseResource{}
 var _ resource.ResourceWithImportState = &TankaReleaseResource{}
+var _ resource.ResourceWithModifyPlan = &TankaReleaseResource{}
+var _ resource.ResourceWithValidateConfig = &TankaReleaseResource{}
 
 func NewTankaReleaseResource() resource.Resource {
 	return &TankaReleaseResource{}
@@ -34,13 +41,24 @@ type TankaReleaseResource struct {
 
 // TankaReleaseResourceModel describes the resource data model.
 type TankaReleaseResourceModel struct {
-	Id             types.String `tfsdk:"id"`
-	Namespace      types.String `tfsdk:"namespace"`
-	Version        types.String `tfsdk:"version"`
-	SourcePath     types.String `tfsdk:"source_path"`
-	Config         types.String `tfsdk:"config"`
-	ConfigOverride types.String `tfsdk:"config_override"`
-	LastUpdated    types.String `tfsdk:"last_updated"`
+	ConfigInputsModel
+
+	Id                  types.String        `tfsdk:"id"`
+	Namespace           types.String        `tfsdk:"namespace"`
+	Version             types.String        `tfsdk:"version"`
+	SourcePath          types.String        `tfsdk:"source_path"`
+	BaseDir             types.String        `tfsdk:"base_dir"`
+	EffectiveConfig     types.Object        `tfsdk:"effective_config"`
+	ConfigHash          types.String        `tfsdk:"config_hash"`
+	ConfigRevisions     types.Map           `tfsdk:"config_revisions"`
+	ConfigSchema        ConfigString        `tfsdk:"config_schema"`
+	SensitiveConfig     ConfigString        `tfsdk:"sensitive_config"`
+	SensitiveConfigHash types.String        `tfsdk:"sensitive_config_hash"`
+	ConfigSources       []ConfigSourceModel `tfsdk:"config_source"`
+	Lint                types.String        `tfsdk:"lint"`
+	FormatCheck         types.String        `tfsdk:"format_check"`
+	HelmCharts          types.String        `tfsdk:"helm_charts"`
+	LastUpdated         types.String        `tfsdk:"last_updated"`
 }
 
 func (r *TankaReleaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -57,7 +75,7 @@ func (r *TankaReleaseResource) Schema(ctx context.Context, req resource.SchemaRe
 				MarkdownDescription: "The Kubernetes namespace to install the release into. Defaults to `default`.",
 				Optional:            true,
 				Computed:            true,
-				Default:             stringdefault.StaticString("default"),
+				Default:             stringdefault.StaticString(defaultNamespace),
 			},
 			"version": schema.StringAttribute{
 				MarkdownDescription: "A version number for the Tanka package. Examples could be a git commit SHA, or a random value to force update on every run. This value is not passed to the tanka application, if version information needs to be available to tanka it should be set as a subkey in one of the config objects.",
@@ -66,23 +84,94 @@ func (r *TankaReleaseResource) Schema(ctx context.Context, req resource.SchemaRe
 				Default:             stringdefault.StaticString("0"),
 			},
 			"source_path": schema.StringAttribute{
-				MarkdownDescription: "The location of the Tanka main file. Defaults to `tanka/environments/default`.",
+				MarkdownDescription: "The location of the Tanka main file. Relative paths are resolved against the base directory, see `base_dir`. Defaults to `tanka/environments/default`.",
 				Optional:            true,
 				Computed:            true,
-				Default:             stringdefault.StaticString("tanka/environments/default"),
+				Default:             stringdefault.StaticString(defaultSourcePath),
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory relative `source_path` and `file://` paths are resolved against. Overrides the `base_dir` of the provider. When neither is set, the working directory of Terraform is used. Set it to `path.module` to make paths relative to the calling module.",
+				Optional:            true,
 			},
 			"config": schema.StringAttribute{
-				MarkdownDescription: "Configuration object in arbitrary JSON format. The data can be provided inline with jsonencode() or given as a file. Local file paths are prefixed with `file://` and remote sources with the correct protocol `http://` or `https://`. Remote sources must be publicly available. Defaults to the empty object.",
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Configuration object in arbitrary JSON format. The data can be provided inline with jsonencode() or given as a file. Local file paths are prefixed with `file://`, environment variables with `env://`, files in git repositories are given as `git::<repo>//<path>?ref=<rev>` and remote sources with the correct protocol `http://` or `https://`. Authentication and other settings for remote sources are set in `config_source` blocks. YAML is converted to JSON when the file or URL ends in `.yaml` or `.yml`, the server responds with a YAML content type, or the input is prefixed with `yaml://` (inline) or `yaml+` followed by the prefix of the source. Inline values are compared by value, so formatting and key order don't cause a change. Defaults to the empty object.",
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("{}"),
 			},
 			"config_override": schema.StringAttribute{
-				MarkdownDescription: "Configuration override object in arbitrary JSON format. The data can be provided inline with jsonencode() or given as a file. Local file paths are prefixed with `file://` and remote sources with the correct protocol `http://` or `https://`. Remote sources must be publicly available. Defaults to the empty object.",
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Configuration override object in arbitrary JSON format. The data can be provided inline with jsonencode() or given as a file. Local file paths are prefixed with `file://`, environment variables with `env://`, files in git repositories are given as `git::<repo>//<path>?ref=<rev>` and remote sources with the correct protocol `http://` or `https://`. Authentication and other settings for remote sources are set in `config_source` blocks. YAML is converted to JSON when the file or URL ends in `.yaml` or `.yml`, the server responds with a YAML content type, or the input is prefixed with `yaml://` (inline) or `yaml+` followed by the prefix of the source. Inline values are compared by value, so formatting and key order don't cause a change. Defaults to the empty object.",
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("{}"),
 			},
+			"config_object": schema.DynamicAttribute{
+				MarkdownDescription: "Configuration object given as a native HCL object, without `jsonencode()`. Plans show the changes of the individual keys. Conflicts with `config` and `config_layers`.",
+				Optional:            true,
+			},
+			"config_override_object": schema.DynamicAttribute{
+				MarkdownDescription: "Configuration override object given as a native HCL object, without `jsonencode()`. Plans show the changes of the individual keys. Conflicts with `config_override` and `config_layers`.",
+				Optional:            true,
+			},
+			"config_layers": schema.ListAttribute{
+				MarkdownDescription: "Ordered list of configuration objects, accepting the same sources as `config`. The layers are merged in order with the semantics of `std.mergePatch()`, so later layers override earlier ones and `null` removes a key. The result is passed to tanka as `tf_config`, while `tf_config_override` is the empty object. Entries that are `null` are skipped. Conflicts with `config`, `config_override`, `config_object` and `config_override_object`.",
+				ElementType:         ConfigStringType{},
+				Optional:            true,
+			},
+			"effective_config": schema.SingleNestedAttribute{
+				MarkdownDescription: "The merged configuration, as seen by the tanka package.",
+				Computed:            true,
+				Attributes: map[string]schema.Attribute{
+					"json": schema.StringAttribute{
+						MarkdownDescription: "The merged configuration in JSON.",
+						Computed:            true,
+					},
+					"layers": schema.MapAttribute{
+						MarkdownDescription: "The index of the layer that last set each top-level key. Without `config_layers`, `config` or `config_object` is layer `0` and `config_override` or `config_override_object` layer `1`.",
+						ElementType:         types.Int64Type,
+						Computed:            true,
+					},
+				},
+			},
+			"config_schema": schema.StringAttribute{
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "JSON Schema the merged configuration objects are validated against during plan. The objects are merged with the semantics of `std.mergePatch()`. The schema can be provided inline with jsonencode() or given as a file, using the same prefixes as `config`. Every violation is reported with the JSON pointer of the offending value, on the config attribute or layer that set its top-level key.",
+				Optional:            true,
+			},
+			"sensitive_config": schema.StringAttribute{
+				CustomType:          ConfigStringType{},
+				MarkdownDescription: "Sensitive configuration object in arbitrary JSON format, accepting the same sources as `config`. SOPS encrypted JSON and YAML sources are decrypted with the age keys of the provider. It is passed to tanka as the separate `tf_sensitive_config` TLA and only when set. The value is write-only and never stored in state or plan, changes are detected through `sensitive_config_hash`. The diff tanka prints before applying is disabled and string values of at least four characters are redacted from error messages. As the value is not available on destroy, the empty object is passed instead. Requires Terraform 1.11 or later.",
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+			},
+			"lint": schema.StringAttribute{
+				MarkdownDescription: "Lint the Jsonnet files of the environment directory during plan, like `tk lint`. Findings such as unused variables are reported with their location as `warning` or `error`. Vendored libraries and hidden files are skipped. Disabled when not set.",
+				Optional:            true,
+			},
+			"format_check": schema.StringAttribute{
+				MarkdownDescription: "Check during plan that the Jsonnet files of the environment directory are formatted, like `tk fmt --test`. Unformatted files are reported at the first line that differs as `warning` or `error`. Vendored libraries and hidden files are skipped. Disabled when not set.",
+				Optional:            true,
+			},
+			"helm_charts": schema.StringAttribute{
+				MarkdownDescription: "Check the Helm charts required by the `chartfile.yaml` closest to the environment before it is evaluated. With `verify` missing charts and charts vendored in another version are an error, with `vendor` they are pulled into the charts directory on apply, like `tk tool charts vendor`, while plans only report them as a warning and skip the evaluation. Charts of `file://` repositories are read from disk, others are pulled with the `helm` binary. Disabled when not set.",
+				Optional:            true,
+			},
+			"config_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the resolved and normalized `config` and `config_override` content, or of the merged `config_layers`. Sources are resolved during plan, so changes to the values of a referenced file or remote document trigger an update.",
+				Computed:            true,
+			},
+			"config_revisions": schema.MapAttribute{
+				MarkdownDescription: "The commit each `git::` source of the config resolved to, keyed by the source.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"sensitive_config_hash": schema.StringAttribute{
+				MarkdownDescription: "Salted SHA-256 hash of the normalized `sensitive_config` used for change detection, in the form `<salt>:<hash>`. Reformatting the JSON does not change the hash.",
+				Computed:            true,
+			},
 			"last_updated": schema.StringAttribute{
 				MarkdownDescription: "Timestamp updated on every apply operation.",
 				Computed:            true,
@@ -95,6 +184,56 @@ func (r *TankaReleaseResource) Schema(ctx context.Context, req resource.SchemaRe
 				},
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"config_source": schema.ListNestedBlock{
+				MarkdownDescription: "Settings used when fetching a remote `http://` or `https://` config source. The block applies to sources whose URL matches `url` exactly, without any `yaml+` prefix.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"url": schema.StringAttribute{
+							MarkdownDescription: "The URL of the remote source the settings apply to.",
+							Required:            true,
+						},
+						"bearer_token": schema.StringAttribute{
+							MarkdownDescription: "Token sent in the `Authorization` header as bearer authentication. Conflicts with `username`.",
+							Optional:            true,
+							Sensitive:           true,
+						},
+						"username": schema.StringAttribute{
+							MarkdownDescription: "Username for basic authentication. Conflicts with `bearer_token`.",
+							Optional:            true,
+						},
+						"password": schema.StringAttribute{
+							MarkdownDescription: "Password for basic authentication.",
+							Optional:            true,
+							Sensitive:           true,
+						},
+						"headers": schema.MapAttribute{
+							MarkdownDescription: "Additional headers sent with the request.",
+							ElementType:         types.StringType,
+							Optional:            true,
+							Sensitive:           true,
+						},
+						"ca_certificate": schema.StringAttribute{
+							MarkdownDescription: "PEM encoded CA bundle trusted in addition to the system certificates.",
+							Optional:            true,
+						},
+						"timeout": schema.StringAttribute{
+							MarkdownDescription: "Timeout of each request as a positive duration, e.g. `10s`. Defaults to `30s`.",
+							Optional:            true,
+						},
+						"retries": schema.Int64Attribute{
+							MarkdownDescription: "Number of times a request is retried on network errors, `429` and `5xx` responses. Defaults to `0`.",
+							Optional:            true,
+						},
+						"sha256": schema.StringAttribute{
+							MarkdownDescription: "Expected SHA-256 checksum of the fetched document in hex. The plan fails if the document doesn't match.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -128,21 +267,63 @@ func (r *TankaReleaseResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
-	config, err := r.client.parseConfig(data.Config.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Marshal Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	opts, diags := r.parseOpts(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	config_override, err := r.client.parseConfig(data.ConfigOverride.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	resolved, diags := r.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err = r.client.Apply(r.client.Endpoint, data.Namespace.ValueString(), config, config_override, data.SourcePath.ValueString())
+	sensitive_config, diags := r.readSensitiveConfig(ctx, req.Config, opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The plan recorded the content of the sources, applying anything else
+	// would not match it
+	config_hash := configContentHash(resolved.Config, resolved.ConfigOverride)
+	if !data.ConfigHash.IsUnknown() && data.ConfigHash.ValueString() != config_hash {
+		resp.Diagnostics.AddError("Config Changed", "The content of the config sources changed after the plan was made, create a new plan to apply the current content.")
+		return
+	}
+	data.ConfigHash = types.StringValue(config_hash)
+
+	config_revisions, diags := configRevisionsValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !data.ConfigRevisions.IsUnknown() && !data.ConfigRevisions.Equal(config_revisions) {
+		resp.Diagnostics.AddError("Config Changed", "The revision of a git config source changed after the plan was made, create a new plan to apply the current revision.")
+		return
+	}
+	data.ConfigRevisions = config_revisions
+
+	data.EffectiveConfig, diags = effectiveConfigValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(syncReleaseHelmCharts(resolvePath(opts.BaseDir, data.SourcePath.ValueString()), data.HelmCharts)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Apply(r.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, sensitive_config, resolvePath(opts.BaseDir, data.SourcePath.ValueString()))
 	if err != nil {
-		resp.Diagnostics.AddError("Apply Error", fmt.Sprintf("Unable to apply tanka package, got error: %s", err))
+		resp.Diagnostics.AddError("Apply Error", fmt.Sprintf("Unable to apply tanka package, got error: %s", redactSensitive(err.Error(), sensitive_config)))
+		return
+	}
+
+	resp.Diagnostics.Append(setSensitiveConfigHash(&data, sensitive_config)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -167,6 +348,15 @@ func (r *TankaReleaseResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	// Releases created before effective_config or config_hash existed get them
+	// filled in, so they don't show up as a change and trigger an apply
+	if (data.EffectiveConfig.IsNull() || data.ConfigHash.IsNull()) && (!data.Config.IsNull() || !data.ConfigLayers.IsNull()) {
+		resp.Diagnostics.Append(r.refreshResolvedConfig(ctx, &data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -181,21 +371,63 @@ func (r *TankaReleaseResource) Update(ctx context.Context, req resource.UpdateRe
 		return
 	}
 
-	config, err := r.client.parseConfig(data.Config.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Marshal Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	opts, diags := r.parseOpts(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	config_override, err := r.client.parseConfig(data.ConfigOverride.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	resolved, diags := r.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sensitive_config, diags := r.readSensitiveConfig(ctx, req.Config, opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The plan recorded the content of the sources, applying anything else
+	// would not match it
+	config_hash := configContentHash(resolved.Config, resolved.ConfigOverride)
+	if !data.ConfigHash.IsUnknown() && data.ConfigHash.ValueString() != config_hash {
+		resp.Diagnostics.AddError("Config Changed", "The content of the config sources changed after the plan was made, create a new plan to apply the current content.")
+		return
+	}
+	data.ConfigHash = types.StringValue(config_hash)
+
+	config_revisions, diags := configRevisionsValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !data.ConfigRevisions.IsUnknown() && !data.ConfigRevisions.Equal(config_revisions) {
+		resp.Diagnostics.AddError("Config Changed", "The revision of a git config source changed after the plan was made, create a new plan to apply the current revision.")
+		return
+	}
+	data.ConfigRevisions = config_revisions
+
+	data.EffectiveConfig, diags = effectiveConfigValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(syncReleaseHelmCharts(resolvePath(opts.BaseDir, data.SourcePath.ValueString()), data.HelmCharts)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err = r.client.Apply(r.client.Endpoint, data.Namespace.ValueString(), config, config_override, data.SourcePath.ValueString())
+	err := r.client.Apply(r.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, sensitive_config, resolvePath(opts.BaseDir, data.SourcePath.ValueString()))
 	if err != nil {
-		resp.Diagnostics.AddError("Apply Error", fmt.Sprintf("Unable to apply tanka package, got error: %s", err))
+		resp.Diagnostics.AddError("Apply Error", fmt.Sprintf("Unable to apply tanka package, got error: %s", redactSensitive(err.Error(), sensitive_config)))
+		return
+	}
+
+	resp.Diagnostics.Append(setSensitiveConfigHash(&data, sensitive_config)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -219,19 +451,25 @@ func (r *TankaReleaseResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	config, err := r.client.parseConfig(data.Config.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Marshal Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	opts, diags := r.parseOpts(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	config_override, err := r.client.parseConfig(data.ConfigOverride.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Parse Error", fmt.Sprintf("Unable to parse json data, got error: %s", err))
+	resolved, diags := r.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err = r.client.Delete(r.client.Endpoint, data.Namespace.ValueString(), config, config_override, data.SourcePath.ValueString())
+	// The write-only sensitive config is not available on destroy
+	sensitive_config := ""
+	if !data.SensitiveConfigHash.IsNull() {
+		sensitive_config = "{}"
+	}
+
+	err := r.client.Delete(r.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, sensitive_config, resolvePath(opts.BaseDir, data.SourcePath.ValueString()))
 	if err != nil {
 		resp.Diagnostics.AddError("Delete Error", fmt.Sprintf("Unable to delete tanka package, got error: %s", err))
 		return
@@ -245,6 +483,301 @@ func (r *TankaReleaseResource) Delete(ctx context.Context, req resource.DeleteRe
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+func (r *TankaReleaseResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateConfigInputs(ctx, req.Config, "config_schema", "sensitive_config")...)
+
+	for _, attribute := range []string{"lint", "format_check"} {
+		var severity types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &severity)...)
+		if severity.IsNull() || severity.IsUnknown() {
+			continue
+		}
+
+		if !slices.Contains(lintSeverities, severity.ValueString()) {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute), "Invalid Severity", fmt.Sprintf("Unknown severity %q, expected one of %s.", severity.ValueString(), strings.Join(lintSeverities, ", ")))
+		}
+	}
+
+	var helm_charts types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("helm_charts"), &helm_charts)...)
+	if !helm_charts.IsNull() && !helm_charts.IsUnknown() && !slices.Contains(helmChartsModes, helm_charts.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("helm_charts"), "Invalid Mode", fmt.Sprintf("Unknown mode %q, expected one of %s.", helm_charts.ValueString(), strings.Join(helmChartsModes, ", ")))
+	}
+}
+
+func (r *TankaReleaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// The provider is not configured yet when only validating
+	if r.client == nil {
+		return
+	}
+
+	// Changes found while planning are not known to the framework, which only
+	// marks computed values as unknown when the configuration changed
+	defer func() {
+		if !req.State.Raw.IsNull() && !resp.Plan.Raw.Equal(req.State.Raw) {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_updated"), types.StringUnknown())...)
+		}
+	}()
+
+	var data TankaReleaseResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if configSourcesUnknown(data.ConfigSources) {
+		tflog.Debug(ctx, "skipping plan-time checks of the tanka package, config_source is not known")
+		return
+	}
+
+	opts, diags := r.parseOpts(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var sensitive ConfigString
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sensitive_config"), &sensitive)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sensitive_config, diags := r.resolveSensitiveConfig(ctx, sensitive, opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stored_hash := types.StringNull()
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("sensitive_config_hash"), &stored_hash)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The sensitive config is write-only, so the hash is the only attribute
+	// that can make a change of it show up in the plan
+	planned_hash := types.StringUnknown()
+	switch {
+	case sensitive.IsUnknown():
+		// Resolved during apply
+	case sensitive.IsNull():
+		planned_hash = types.StringNull()
+	default:
+		if !stored_hash.IsNull() && !stored_hash.IsUnknown() && !sensitiveConfigChanged(sensitive_config, stored_hash.ValueString()) {
+			planned_hash = stored_hash
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sensitive_config_hash"), planned_hash)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if sensitive.IsUnknown() || data.Namespace.IsUnknown() || data.SourcePath.IsUnknown() || data.BaseDir.IsUnknown() || data.configInputs().unknown() {
+		tflog.Debug(ctx, "skipping plan-time evaluation of the tanka package, not all values are known")
+		return
+	}
+
+	// Sources are resolved on every plan, so pinned checksums are verified
+	// even when nothing else changed
+	resolved, diags := r.client.resolveInputs(ctx, data.configInputs(), opts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	source_path := resolvePath(opts.BaseDir, data.SourcePath.ValueString())
+	if _, err := os.Stat(source_path); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source_path"), "Source Path Error", fmt.Sprintf("Unable to find the tanka environment at %s, got error: %s", source_path, err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("config_hash"), configContentHash(resolved.Config, resolved.ConfigOverride))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	effective_config, diags := effectiveConfigValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("effective_config"), effective_config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config_revisions, diags := configRevisionsValue(resolved)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("config_revisions"), config_revisions)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The sources are checked on every plan, as changes to them don't show up
+	// in the plan by themselves
+	resp.Diagnostics.Append(checkEnvironmentSources(source_path, data.Lint, data.FormatCheck)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Charts are only verified, vendoring them waits for the apply
+	charts_vendored, diags := planReleaseHelmCharts(source_path, data.HelmCharts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Charts missing from the vendor directory are a change of their own, the
+	// update vendors them
+	if !charts_vendored && !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_updated"), types.StringUnknown())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Evaluate the tanka package for planned changes, so errors show up
+	// before any other resource is applied
+	if !req.State.Raw.IsNull() && resp.Plan.Raw.Equal(req.State.Raw) {
+		return
+	}
+
+	if !data.ConfigSchema.IsNull() && !data.ConfigSchema.IsUnknown() {
+		config_schema, err := r.client.parseConfig(ctx, data.ConfigSchema.ValueString(), opts)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("config_schema"), "Parse Error", fmt.Sprintf("Unable to parse json schema, got error: %s", err))
+			return
+		}
+
+		violations, err := validateConfigSchema(config_schema, resolved.Config, resolved.ConfigOverride)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("config_schema"), "Schema Error", fmt.Sprintf("Unable to validate config against json schema, got error: %s", err))
+			return
+		}
+
+		for _, violation := range violations {
+			attribute := configSchemaAttribute(data.configInputs(), resolved.Layers, violation.Pointer)
+			resp.Diagnostics.AddAttributeError(attribute, "Config Schema Violation", fmt.Sprintf("%s: %s", violation.Pointer, violation.Message))
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The environment can't be evaluated before its charts are vendored
+	if !charts_vendored {
+		return
+	}
+
+	_, err := r.client.Show(r.client.Endpoint, data.Namespace.ValueString(), resolved.Config, resolved.ConfigOverride, sensitive_config, source_path, "", nil)
+	if err != nil {
+		resp.Diagnostics.Append(jsonnetErrorDiagnostic(err, sensitive_config))
+		return
+	}
+}
+
+// parseOpts collects the settings used to resolve the config inputs of the
+// release.
+func (r *TankaReleaseResource) parseOpts(ctx context.Context, data *TankaReleaseResourceModel) (ParseOpts, diag.Diagnostics) {
+	return r.client.parseOpts(ctx, data.BaseDir, data.ConfigSources)
+}
+
+// refreshResolvedConfig resolves the inputs stored in state and sets the
+// computed attributes derived from them that are missing. Sources that can't
+// be resolved leave them unset, the next plan reports the problem. As the
+// state holds no earlier hash, source changes made before the refresh are
+// taken as the applied content.
+func (r *TankaReleaseResource) refreshResolvedConfig(ctx context.Context, data *TankaReleaseResourceModel) (diags diag.Diagnostics) {
+	if r.client == nil || configSourcesUnknown(data.ConfigSources) {
+		return nil
+	}
+
+	opts, diags := r.parseOpts(ctx, data)
+	if diags.HasError() {
+		return nil
+	}
+
+	resolved, diags := r.client.resolveInputs(ctx, data.configInputs(), opts)
+	if diags.HasError() {
+		tflog.Debug(ctx, "unable to resolve the config of the release, the computed config attributes are left unset")
+		return nil
+	}
+
+	if data.EffectiveConfig.IsNull() {
+		data.EffectiveConfig, diags = effectiveConfigValue(resolved)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if data.ConfigHash.IsNull() {
+		data.ConfigHash = types.StringValue(configContentHash(resolved.Config, resolved.ConfigOverride))
+		data.ConfigRevisions, diags = configRevisionsValue(resolved)
+	}
+
+	return diags
+}
+
+// readSensitiveConfig resolves the write-only sensitive config. It is only
+// available from the configuration, never from plan or state.
+func (r *TankaReleaseResource) readSensitiveConfig(ctx context.Context, config tfsdk.Config, opts ParseOpts) (sensitive_config string, diags diag.Diagnostics) {
+	var sensitive ConfigString
+	diags.Append(config.GetAttribute(ctx, path.Root("sensitive_config"), &sensitive)...)
+	if diags.HasError() {
+		return
+	}
+
+	return r.resolveSensitiveConfig(ctx, sensitive, opts)
+}
+
+// resolveSensitiveConfig resolves the sensitive config read from the
+// configuration, it is empty when not set or not known yet.
+func (r *TankaReleaseResource) resolveSensitiveConfig(ctx context.Context, sensitive ConfigString, opts ParseOpts) (string, diag.Diagnostics) {
+	if sensitive.IsNull() || sensitive.IsUnknown() {
+		return "", nil
+	}
+
+	// The sensitive config is never persisted, so it may hold decrypted data
+	opts.Decrypt = true
+
+	return r.client.resolveConfig(ctx, path.Root("sensitive_config"), sensitive.ValueString(), opts)
+}
+
+// setSensitiveConfigHash stores a new salted hash when the plan left it
+// unknown, and clears it when no sensitive config is set.
+func setSensitiveConfigHash(data *TankaReleaseResourceModel, sensitive_config string) (diags diag.Diagnostics) {
+	if sensitive_config == "" {
+		data.SensitiveConfigHash = types.StringNull()
+		return
+	}
+
+	if !data.SensitiveConfigHash.IsUnknown() {
+		return
+	}
+
+	hash, err := hashSensitiveConfig(sensitive_config, "")
+	if err != nil {
+		diags.AddError("Hash Error", fmt.Sprintf("Unable to hash sensitive config, got error: %s", err))
+		return
+	}
+	data.SensitiveConfigHash = types.StringValue(hash)
+
+	return
+}
+
 func (r *TankaReleaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const sensitiveRedacted = "(sensitive value)"
+
+// sensitiveRedactMinLength is the length string values need to be redacted.
+// Shorter ones, like `a` or `on`, would scrub unrelated words and keywords
+// from every message.
+const sensitiveRedactMinLength = 4
+
+// hashSensitiveConfig returns a salted SHA-256 hash of the normalized sensitive
+// config in the form `<salt>:<hash>`. A new salt is generated when none is
+// given.
+func hashSensitiveConfig(sensitive_config, salt string) (string, error) {
+	if salt == "" {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return "", err
+		}
+		salt = hex.EncodeToString(raw)
+	}
+
+	// Reformatting the config doesn't change the hash, like the other config inputs
+	if normalized, err := normalizeJSON(sensitive_config); err == nil {
+		sensitive_config = normalized
+	}
+
+	sum := sha256.Sum256([]byte(salt + sensitive_config))
+	return salt + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// sensitiveConfigChanged reports whether the sensitive config no longer
+// matches the salted hash stored in state.
+func sensitiveConfigChanged(sensitive_config, stored_hash string) bool {
+	salt, _, found := strings.Cut(stored_hash, ":")
+	if !found {
+		return true
+	}
+
+	hash, err := hashSensitiveConfig(sensitive_config, salt)
+	if err != nil {
+		return true
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(stored_hash)) != 1
+}
+
+// redactSensitive replaces the sensitive config, and every string value found
+// inside it, with a placeholder so it cannot leak through diagnostics. Numbers,
+// booleans and short strings are left alone.
+func redactSensitive(message, sensitive_config string) string {
+	if sensitive_config == "" {
+		return message
+	}
+
+	secrets := []string{sensitive_config}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(sensitive_config), &data); err == nil {
+		secrets = append(secrets, collectStrings(data)...)
+	}
+
+	// Replace longer values first so substrings don't leave partial secrets behind
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	for _, secret := range secrets {
+		if len(secret) < sensitiveRedactMinLength {
+			continue
+		}
+		message = strings.ReplaceAll(message, secret, sensitiveRedacted)
+	}
+
+	return message
+}
+
+func collectStrings(data interface{}) (values []string) {
+	switch v := data.(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, collectStrings(item)...)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			values = append(values, collectStrings(item)...)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+package provider
+
+import "testing"
+
+func TestRedactSensitive(t *testing.T) {
+	tests := []struct {
+		name             string
+		message          string
+		sensitive_config string
+		expected         string
+	}{
+		{
+			name:             "no sensitive config",
+			message:          "password hunter2",
+			sensitive_config: "",
+			expected:         "password hunter2",
+		},
+		{
+			name:             "string values",
+			message:          "login with admin:hunter2 failed",
+			sensitive_config: `{"user": "admin", "password": "hunter2"}`,
+			expected:         "login with (sensitive value):(sensitive value) failed",
+		},
+		{
+			name:             "longer values first",
+			message:          "token abcdef",
+			sensitive_config: `{"short": "abc", "long": "abcdef"}`,
+			expected:         "token (sensitive value)",
+		},
+		{
+			name:             "nested values",
+			message:          "key s3cr3t",
+			sensitive_config: `{"db": {"keys": ["s3cr3t"]}}`,
+			expected:         "key (sensitive value)",
+		},
+		{
+			name:             "short strings",
+			message:          "a value on line 12 is not valid",
+			sensitive_config: `{"flag": "a", "mode": "on", "token": "hunter2"}`,
+			expected:         "a value on line 12 is not valid",
+		},
+		{
+			name:             "numbers and booleans",
+			message:          "expected true at line 1234",
+			sensitive_config: `{"pin": 1234, "debug": true}`,
+			expected:         "expected true at line 1234",
+		},
+		{
+			name:             "raw config",
+			message:          "invalid config not json",
+			sensitive_config: "not json",
+			expected:         "invalid config (sensitive value)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := redactSensitive(test.message, test.sensitive_config)
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSensitiveConfigChanged(t *testing.T) {
+	stored_hash, err := hashSensitiveConfig(`{"password": "hunter2"}`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sensitiveConfigChanged(`{"password": "hunter2"}`, stored_hash) {
+		t.Error("expected the same config to be unchanged")
+	}
+	if sensitiveConfigChanged("{\n  \"password\":\"hunter2\"\n}\n", stored_hash) {
+		t.Error("expected the reformatted config to be unchanged")
+	}
+	if !sensitiveConfigChanged(`{"password": "hunter3"}`, stored_hash) {
+		t.Error("expected the modified config to be changed")
+	}
+	if !sensitiveConfigChanged(`{"password": "hunter2"}`, "invalid") {
+		t.Error("expected a malformed hash to be changed")
+	}
+}